@@ -0,0 +1,67 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"encoding/base64"
+	"github.com/gdamore/tcell"
+)
+
+// CopyableItem is a ListItem that can be copied to clipboard in ScrollList.
+type CopyableItem interface {
+	ListItem
+	// CopyText returns text that is copied to clipboard.
+	CopyText() string
+}
+
+// WriteClipboard sends text to system clipboard with OSC 52 escape sequence. This works over SSH too,
+// if terminal supports it. Return false if screen cannot send escape sequences.
+func WriteClipboard(screen tcell.Screen, text string) bool {
+	writer, ok := screen.(interface{ TPuts(s string) })
+	if !ok {
+		return false
+	}
+	writer.TPuts("\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a")
+	return true
+}
+
+// clipboard holds copied text until it can be sent to screen on next draw.
+type clipboard struct {
+	pending  string
+	copied   bool
+	copyFunc func(text string, osc52 bool)
+}
+
+// copy queues text to be sent to clipboard
+func (c *clipboard) copy(text string) {
+	c.pending = text
+	c.copied = true
+}
+
+// flush sends queued text to clipboard, if any
+func (c *clipboard) flush(screen tcell.Screen) {
+	if !c.copied {
+		return
+	}
+	text := c.pending
+	c.pending = ""
+	c.copied = false
+	sent := WriteClipboard(screen, text)
+	if c.copyFunc != nil {
+		c.copyFunc(text, sent)
+	}
+}
@@ -30,14 +30,16 @@ func main() {
 	app = cview.NewApplication()
 
 	colors := twidgets.NavBarColors{
-		Background:            tcell.Color235,
-		BackgroundFocus:       tcell.Color235,
-		ButtonBackground:      tcell.Color235,
-		ButtonBackgroundFocus: tcell.Color23,
-		Text:                  tcell.Color252,
-		TextFocus:             tcell.Color253,
-		Shortcut:              tcell.Color214,
-		ShortcutFocus:         tcell.Color214,
+		Background:               tcell.Color235,
+		BackgroundFocus:          tcell.Color235,
+		ButtonBackground:         tcell.Color235,
+		ButtonBackgroundFocus:    tcell.Color23,
+		Text:                     tcell.Color252,
+		TextFocus:                tcell.Color253,
+		Shortcut:                 tcell.Color214,
+		ShortcutFocus:            tcell.Color214,
+		ButtonBackgroundDisabled: tcell.Color235,
+		TextDisabled:             tcell.Color242,
 	}
 	navBar := twidgets.NewNavBar(&colors, done)
 
@@ -53,6 +55,12 @@ func main() {
 	btn = cview.NewButton("fourth")
 	navBar.AddButton(btn, tcell.KeyF4)
 
+	btn = cview.NewButton("quit")
+	err := navBar.AddButtonShortcut(btn, twidgets.RuneShortcut('q', tcell.ModNone))
+	if err != nil {
+		panic(err)
+	}
+
 	app.SetRoot(navBar, true)
 	app.Run()
 }
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell"
 	"gitlab.com/tslocum/cview"
+	"sync"
 )
 
 // NacBarColors are colors that fully describe navbar look
@@ -32,6 +33,9 @@ type NavBarColors struct {
 	TextFocus             tcell.Color
 	Shortcut              tcell.Color
 	ShortcutFocus         tcell.Color
+	// Disabled button colors. Set to tcell.ColorDefault to use ButtonBackground and dimmed text.
+	ButtonBackgroundDisabled tcell.Color
+	TextDisabled             tcell.Color
 }
 
 // NavBar implements navigation bar with multiple buttons. Buttons can be added one by one, each one having
@@ -39,15 +43,32 @@ type NavBarColors struct {
 type NavBar struct {
 	grid      *cview.Grid
 	buttons   []*cview.Button
-	btnKeys   []tcell.Key
+	btnKeys   []Shortcut
 	btnLabels []string
+	btnPages  []string
+	btnMenus  []*Menu
 	doneFunc  func(label string)
 	colors    *NavBarColors
+	pages     *cview.Pages
 
 	// Which button is active
 	btnActiveIndex int
 	hasFocus       bool
 	visible        bool
+
+	overflow *navBarOverflow
+	menus    []*menuLevel
+	// menuButton is button whose menu is open
+	menuButton *cview.Button
+
+	redrawFunc  func()
+	badgeLock   sync.Mutex
+	badges      map[*cview.Button]navBarBadge
+	badgesDirty bool
+
+	disabled map[*cview.Button]bool
+	hidden   map[*cview.Button]bool
+	hover    *cview.Button
 }
 
 func (n *NavBar) GetVisible() bool {
@@ -59,11 +80,27 @@ func (n *NavBar) SetVisible(v bool) {
 }
 
 func (n *NavBar) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
-	return n.grid.MouseHandler()
+	return func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+		if n.menuMouse(action, event) {
+			return true, nil
+		}
+		if n.overflowMouse(action, event, setFocus) {
+			return true, nil
+		}
+		if n.buttonMouse(action, event, setFocus) {
+			return true, nil
+		}
+		return n.grid.MouseHandler()(action, event, setFocus)
+	}
 }
 
 func (n *NavBar) Draw(screen tcell.Screen) {
+	n.updateTabs()
+	n.updateBadges()
+	n.layoutButtons()
 	n.grid.Draw(screen)
+	n.drawOverflow(screen)
+	n.drawMenus(screen)
 }
 
 func (n *NavBar) GetRect() (int, int, int, int) {
@@ -72,31 +109,38 @@ func (n *NavBar) GetRect() (int, int, int, int) {
 
 func (n *NavBar) SetRect(x, y, width, height int) {
 	n.grid.SetRect(x, y, width, height)
+	n.layoutButtons()
 }
 
 func (n *NavBar) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
 	return func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
-		lastBtn := n.btnActiveIndex
+		if len(n.buttons) == 0 {
+			return
+		}
+		if n.menuInput(event) {
+			return
+		}
+		if n.overflowInput(event) {
+			return
+		}
+		if n.pages != nil && n.tabInput(event) {
+			return
+		}
 
 		key := event.Key()
 		if key == tcell.KeyRight {
-			n.btnActiveIndex = min(len(n.buttons)-1, n.btnActiveIndex+1)
+			n.moveActive(n.nextButton(n.btnActiveIndex, 1, false))
 		} else if key == tcell.KeyLeft {
-			n.btnActiveIndex = max(0, n.btnActiveIndex-1)
-		}
-
-		if lastBtn != n.btnActiveIndex {
-			n.buttons[lastBtn].Blur()
-			n.buttons[n.btnActiveIndex].Focus(nil)
+			n.moveActive(n.nextButton(n.btnActiveIndex, -1, false))
 		}
 
 		if key == tcell.KeyEnter {
-			n.callDone(n.btnLabels[n.btnActiveIndex])
+			n.activateButton(n.btnActiveIndex)
 		}
 
 		for i, v := range n.btnKeys {
-			if key == v {
-				n.callDone(n.btnLabels[i])
+			if v.Matches(event) && n.buttonSelectable(i) {
+				n.activateButton(i)
 				break
 			}
 		}
@@ -107,11 +151,27 @@ func (n *NavBar) InputHandler() func(event *tcell.EventKey, setFocus func(p cvie
 func (n *NavBar) Focus(delegate func(p cview.Primitive)) {
 	n.grid.Focus(delegate)
 	n.hasFocus = true
-	n.buttons[n.btnActiveIndex].Focus(nil)
+	if len(n.buttons) > 0 {
+		if !n.buttonSelectable(n.btnActiveIndex) {
+			n.btnActiveIndex = n.nextButton(n.btnActiveIndex, 1, true)
+		}
+		n.buttons[n.btnActiveIndex].Focus(nil)
+	}
 }
 
 func (n *NavBar) Blur() {
-	n.btnActiveIndex = 0
+	if len(n.buttons) > 0 {
+		n.buttons[n.btnActiveIndex].Blur()
+	}
+	// In tab mode active button is the current tab and it is kept
+	if n.pages == nil {
+		n.btnActiveIndex = 0
+		if len(n.buttons) > 0 && !n.buttonSelectable(0) {
+			n.btnActiveIndex = n.nextButton(0, 1, false)
+		}
+	}
+	n.closeOverflow()
+	n.CloseMenu()
 	n.hasFocus = false
 	n.grid.Blur()
 }
@@ -126,10 +186,13 @@ func NewNavBar(colors *NavBarColors, doneFunc func(label string)) *NavBar {
 	nav := &NavBar{
 		grid:      cview.NewGrid(),
 		buttons:   []*cview.Button{},
-		btnKeys:   []tcell.Key{},
+		btnKeys:   []Shortcut{},
 		btnLabels: []string{},
 		doneFunc:  doneFunc,
 		colors:    colors,
+		overflow:  &navBarOverflow{},
+		disabled:  map[*cview.Button]bool{},
+		hidden:    map[*cview.Button]bool{},
 	}
 
 	nav.grid.SetBorders(false)
@@ -140,29 +203,162 @@ func NewNavBar(colors *NavBarColors, doneFunc func(label string)) *NavBar {
 	return nav
 }
 
-//AddButton adds a new button to right side of existing buttons. Key is used to print and highlight key to user
+//AddButton adds a new button to right side of existing buttons. Key is used to print and highlight key to user.
+// If key is already used by another button, button is not added. Use AddButtonShortcut to get an error instead.
 func (n *NavBar) AddButton(button *cview.Button, key tcell.Key) {
-	n.buttons = append(n.buttons, button)
-	n.btnKeys = append(n.btnKeys, key)
-	n.btnLabels = append(n.btnLabels, button.GetLabel())
+	n.InsertButton(len(n.buttons), button, KeyShortcut(key))
+}
+
+// AddButtonShortcut adds a new button to right side of existing buttons. Shortcut can be any key, rune or
+// combination with modifiers. If shortcut is already used by another button, error is returned
+// and button is not added. Zero shortcut adds button without shortcut.
+func (n *NavBar) AddButtonShortcut(button *cview.Button, shortcut Shortcut) error {
+	return n.InsertButton(len(n.buttons), button, shortcut)
+}
+
+// InsertButton inserts button at index. Buttons at and after index are moved right.
+// If shortcut is already used by another button, error is returned and button is not added.
+func (n *NavBar) InsertButton(index int, button *cview.Button, shortcut Shortcut) error {
+	if i := n.shortcutIndex(shortcut); i >= 0 {
+		return fmt.Errorf("shortcut %s already used by button %s", shortcut, n.btnLabels[i])
+	}
+	index = max(0, min(index, len(n.buttons)))
+
+	label := button.GetLabel()
+	n.buttons = append(n.buttons[:index], append([]*cview.Button{button}, n.buttons[index:]...)...)
+	n.btnKeys = append(n.btnKeys[:index], append([]Shortcut{shortcut}, n.btnKeys[index:]...)...)
+	n.btnLabels = append(n.btnLabels[:index], append([]string{label}, n.btnLabels[index:]...)...)
+	n.btnPages = append(n.btnPages[:index], append([]string{""}, n.btnPages[index:]...)...)
+	n.btnMenus = append(n.btnMenus[:index], append([]*Menu{nil}, n.btnMenus[index:]...)...)
+	if index <= n.btnActiveIndex && len(n.buttons) > 1 {
+		n.btnActiveIndex++
+	}
+
 	button.SetBorder(false)
-	button.SetSelectedFunc(wrapKeyFunc(button.GetLabel(), n.doneFunc))
-	button.SetBackgroundColor(n.colors.ButtonBackground)
-	button.SetBackgroundColorActivated(n.colors.ButtonBackgroundFocus)
-	button.SetLabelColor(n.colors.Text)
-	button.SetLabelColorActivated(n.colors.TextFocus)
+	button.SetSelectedFunc(func() {
+		if i := n.buttonIndex(button); i >= 0 {
+			n.activateButton(i)
+		}
+	})
+	n.styleButton(index)
 
-	hex := n.colors.Shortcut.Hex()
+	n.rebuildGrid()
+	return nil
+}
+
+// RemoveButton removes button at index.
+func (n *NavBar) RemoveButton(index int) {
+	if index < 0 || index >= len(n.buttons) {
+		return
+	}
+	active := n.buttons[n.btnActiveIndex]
+	removed := n.buttons[index]
+	n.buttons = append(n.buttons[:index], n.buttons[index+1:]...)
+	n.btnKeys = append(n.btnKeys[:index], n.btnKeys[index+1:]...)
+	n.btnLabels = append(n.btnLabels[:index], n.btnLabels[index+1:]...)
+	n.btnPages = append(n.btnPages[:index], n.btnPages[index+1:]...)
+	n.btnMenus = append(n.btnMenus[:index], n.btnMenus[index+1:]...)
+
+	if index < n.btnActiveIndex {
+		n.btnActiveIndex--
+	}
+	n.btnActiveIndex = max(0, min(n.btnActiveIndex, len(n.buttons)-1))
+	if removed == active {
+		removed.Blur()
+		if n.hasFocus && len(n.buttons) > 0 {
+			n.buttons[n.btnActiveIndex].Focus(nil)
+		}
+	}
+	n.rebuildGrid()
+}
+
+// MoveButton moves button from index to another index.
+func (n *NavBar) MoveButton(from, to int) {
+	if from < 0 || from >= len(n.buttons) || to < 0 || to >= len(n.buttons) || from == to {
+		return
+	}
+	active := n.buttons[n.btnActiveIndex]
+	button, shortcut, label, page := n.buttons[from], n.btnKeys[from], n.btnLabels[from], n.btnPages[from]
+	menu := n.btnMenus[from]
+	n.buttons = append(n.buttons[:from], n.buttons[from+1:]...)
+	n.btnKeys = append(n.btnKeys[:from], n.btnKeys[from+1:]...)
+	n.btnLabels = append(n.btnLabels[:from], n.btnLabels[from+1:]...)
+	n.btnPages = append(n.btnPages[:from], n.btnPages[from+1:]...)
+	n.btnMenus = append(n.btnMenus[:from], n.btnMenus[from+1:]...)
+
+	n.buttons = append(n.buttons[:to], append([]*cview.Button{button}, n.buttons[to:]...)...)
+	n.btnKeys = append(n.btnKeys[:to], append([]Shortcut{shortcut}, n.btnKeys[to:]...)...)
+	n.btnLabels = append(n.btnLabels[:to], append([]string{label}, n.btnLabels[to:]...)...)
+	n.btnPages = append(n.btnPages[:to], append([]string{page}, n.btnPages[to:]...)...)
+	n.btnMenus = append(n.btnMenus[:to], append([]*Menu{menu}, n.btnMenus[to:]...)...)
+
+	n.btnActiveIndex = n.buttonIndex(active)
+	n.rebuildGrid()
+}
+
+// SetButtonLabel changes label of button at index. New label is passed to doneFunc when button is selected.
+func (n *NavBar) SetButtonLabel(index int, label string) {
+	if index < 0 || index >= len(n.buttons) {
+		return
+	}
+	n.btnLabels[index] = label
+	n.styleButton(index)
+	n.rebuildGrid()
+}
 
-	label := fmt.Sprintf("[#%02x]%s[-] %s", hex, tcell.KeyNames[key], button.GetLabel())
-	button.SetLabel(label)
+// GetButtonCount returns number of buttons.
+func (n *NavBar) GetButtonCount() int {
+	return len(n.buttons)
+}
 
-	count := len(n.buttons)
-	n.grid.AddItem(button, 0, 2*count, 1, 1, 1, 5, false)
+// GetButtonLabel returns label of button at index, without shortcut.
+func (n *NavBar) GetButtonLabel(index int) string {
+	if index < 0 || index >= len(n.btnLabels) {
+		return ""
+	}
+	return n.btnLabels[index]
+}
 
-	widths := make([]int, len(n.buttons)*2+1)
+// shortcutIndex returns index of button that has shortcut, or -1
+func (n *NavBar) shortcutIndex(shortcut Shortcut) int {
+	if shortcut.IsZero() {
+		return -1
+	}
+	for i, v := range n.btnKeys {
+		if v.normalize() == shortcut.normalize() {
+			return i
+		}
+	}
+	return -1
+}
+
+// buttonIndex returns index of button, or -1
+func (n *NavBar) buttonIndex(button *cview.Button) int {
+	for i, v := range n.buttons {
+		if v == button {
+			return i
+		}
+	}
+	return -1
+}
+
+// rebuildGrid puts buttons to grid in their order
+func (n *NavBar) rebuildGrid() {
+	n.overflow.dirty = true
+	n.layoutButtons()
+}
+
+// fillGrid puts all buttons to grid with equal widths
+func (n *NavBar) fillGrid() {
+	n.grid.Clear()
+	visible := n.visibleButtons()
+	for i, index := range visible {
+		n.grid.AddItem(n.buttons[index], 0, 2*(i+1), 1, 1, 1, 5, false)
+	}
+
+	widths := make([]int, len(visible)*2+1)
 	spaceWidth := -1
-	for i := 0; i < len(n.buttons)+1; i++ {
+	for i := 0; i < len(visible)+1; i++ {
 		widths[i*2] = -2
 		if i > 0 {
 			widths[i*2-1] = spaceWidth
@@ -172,10 +368,36 @@ func (n *NavBar) AddButton(button *cview.Button, key tcell.Key) {
 	n.grid.SetColumns(widths...)
 }
 
-func wrapKeyFunc(label string, doneFunc func(label string)) func() {
-	return func() {
-		doneFunc(label)
+// buttonLabel returns label of button at index with highlighted shortcut and badge
+func (n *NavBar) buttonLabel(index int) string {
+	label, shortcut, badge := n.btnLabels[index], n.btnKeys[index], n.badge(n.buttons[index])
+	if badge.text != "" {
+		label = fmt.Sprintf("%s [#%06x]%s[-]", label, badge.color.Hex(), cview.Escape(badge.text))
+	}
+	if shortcut.IsZero() {
+		return label
+	}
+	hex := n.colors.Shortcut.Hex()
+	if n.disabled[n.buttons[index]] {
+		_, text := n.disabledColors()
+		hex = text.Hex()
+	}
+	return fmt.Sprintf("[#%06x]%s[-] %s", hex, cview.Escape(shortcut.String()), label)
+}
+
+// activateButton selects button at index as if user pressed it
+func (n *NavBar) activateButton(index int) {
+	if !n.buttonSelectable(index) {
+		return
+	}
+	if n.btnMenus[index] != nil {
+		n.openMenu(index)
+		return
+	}
+	if n.pages != nil {
+		n.selectTab(index)
 	}
+	n.callDone(n.btnLabels[index])
 }
 
 func (n *NavBar) callDone(label string) {
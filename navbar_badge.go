@@ -0,0 +1,91 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strconv"
+)
+
+// navBarBadge is a short text shown after button label
+type navBarBadge struct {
+	text  string
+	color tcell.Color
+}
+
+// SetRedrawFunc sets function that is called when navbar needs to be redrawn after badge was changed,
+// e.g. cview.Application.Draw.
+func (n *NavBar) SetRedrawFunc(redraw func()) {
+	n.redrawFunc = redraw
+}
+
+// SetBadge shows badge after label of button, e.g. an error marker or a spinner glyph. Empty text removes
+// badge. Badge is not part of label passed to doneFunc. This is safe to call from any goroutine,
+// badge is shown on next draw.
+func (n *NavBar) SetBadge(button *cview.Button, text string, color tcell.Color) {
+	n.badgeLock.Lock()
+	if n.badges == nil {
+		n.badges = map[*cview.Button]navBarBadge{}
+	}
+	if text == "" {
+		delete(n.badges, button)
+	} else {
+		n.badges[button] = navBarBadge{text: text, color: color}
+	}
+	n.badgesDirty = true
+	n.badgeLock.Unlock()
+
+	if n.redrawFunc != nil {
+		n.redrawFunc()
+	}
+}
+
+// SetBadgeCount shows count in parenthesis after label of button, e.g. 'Downloads (3)'.
+// Count of 0 or less removes badge. See SetBadge.
+func (n *NavBar) SetBadgeCount(button *cview.Button, count int, color tcell.Color) {
+	text := ""
+	if count > 0 {
+		text = "(" + strconv.Itoa(count) + ")"
+	}
+	n.SetBadge(button, text, color)
+}
+
+// GetBadge returns badge text of button.
+func (n *NavBar) GetBadge(button *cview.Button) string {
+	return n.badge(button).text
+}
+
+// badge returns badge of button
+func (n *NavBar) badge(button *cview.Button) navBarBadge {
+	n.badgeLock.Lock()
+	defer n.badgeLock.Unlock()
+	return n.badges[button]
+}
+
+// updateBadges updates button labels if badges have changed
+func (n *NavBar) updateBadges() {
+	n.badgeLock.Lock()
+	dirty := n.badgesDirty
+	n.badgesDirty = false
+	n.badgeLock.Unlock()
+	if !dirty {
+		return
+	}
+	n.styleButtons()
+	n.rebuildGrid()
+}
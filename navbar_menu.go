@@ -0,0 +1,365 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strings"
+)
+
+// Menu is a dropdown menu that is opened from NavBar button.
+type Menu struct {
+	items []*MenuItem
+}
+
+// MenuItem is a single entry in menu. Item either runs an action or opens a submenu.
+type MenuItem struct {
+	label     string
+	shortcut  Shortcut
+	action    func()
+	submenu   *Menu
+	disabled  bool
+	separator bool
+}
+
+// NewMenu creates new empty menu.
+func NewMenu() *Menu {
+	return &Menu{items: []*MenuItem{}}
+}
+
+// AddItem adds item that calls action when selected. Shortcut is only shown as a hint next to the label,
+// handling the key is up to application. Zero shortcut shows no hint.
+func (m *Menu) AddItem(label string, shortcut Shortcut, action func()) *MenuItem {
+	item := &MenuItem{label: label, shortcut: shortcut, action: action}
+	m.items = append(m.items, item)
+	return item
+}
+
+// AddSubmenu adds item that opens submenu.
+func (m *Menu) AddSubmenu(label string, submenu *Menu) *MenuItem {
+	item := &MenuItem{label: label, submenu: submenu}
+	m.items = append(m.items, item)
+	return item
+}
+
+// AddSeparator adds horizontal line between items.
+func (m *Menu) AddSeparator() *Menu {
+	m.items = append(m.items, &MenuItem{separator: true})
+	return m
+}
+
+// GetItems returns items of menu.
+func (m *Menu) GetItems() []*MenuItem {
+	return m.items
+}
+
+// GetLabel returns label of item.
+func (i *MenuItem) GetLabel() string {
+	return i.label
+}
+
+// SetDisabled sets whether item can be selected. Disabled items are shown dimmed.
+func (i *MenuItem) SetDisabled(disabled bool) *MenuItem {
+	i.disabled = disabled
+	return i
+}
+
+// IsDisabled returns true if item cannot be selected.
+func (i *MenuItem) IsDisabled() bool {
+	return i.disabled
+}
+
+// selectable returns true if item can be selected
+func (i *MenuItem) selectable() bool {
+	return !i.separator && !i.disabled
+}
+
+// menuLevel is an open menu or submenu
+type menuLevel struct {
+	menu     *Menu
+	selected int
+	box      *cview.Box
+}
+
+// move selects next selectable item in direction, wrapping around
+func (l *menuLevel) move(direction int) {
+	count := len(l.menu.items)
+	for i := 1; i <= count; i++ {
+		index := ((l.selected+direction*i)%count + count) % count
+		if l.menu.items[index].selectable() {
+			l.selected = index
+			return
+		}
+	}
+}
+
+// SetButtonMenu sets menu for button at index. Selecting button opens the menu instead of calling
+// doneFunc. Menu is navigated with arrows: up and down move in menu, right opens submenu and left closes it.
+// At top level left and right move to menu of previous or next button. Enter selects item,
+// Escape closes menu, as does click outside menu. Nil menu removes menu from button.
+func (n *NavBar) SetButtonMenu(index int, menu *Menu) {
+	if index < 0 || index >= len(n.buttons) {
+		return
+	}
+	n.btnMenus[index] = menu
+}
+
+// AddMenuButton adds a new button with a menu to right side of existing buttons. See SetButtonMenu.
+// If shortcut is already used by another button, error is returned and button is not added.
+func (n *NavBar) AddMenuButton(button *cview.Button, shortcut Shortcut, menu *Menu) error {
+	err := n.InsertButton(len(n.buttons), button, shortcut)
+	if err != nil {
+		return err
+	}
+	n.btnMenus[len(n.buttons)-1] = menu
+	return nil
+}
+
+// IsMenuOpen returns true if a menu is open.
+func (n *NavBar) IsMenuOpen() bool {
+	return len(n.menus) > 0
+}
+
+// CloseMenu closes open menu and its submenus.
+func (n *NavBar) CloseMenu() {
+	n.menus = nil
+	n.menuButton = nil
+}
+
+// openMenu opens menu of button at index
+func (n *NavBar) openMenu(index int) {
+	n.CloseMenu()
+	n.setActive(index)
+	menu := n.btnMenus[index]
+	if len(menu.items) == 0 {
+		return
+	}
+	n.menuButton = n.buttons[index]
+	n.pushMenu(menu)
+}
+
+// pushMenu opens menu on top of open menus
+func (n *NavBar) pushMenu(menu *Menu) {
+	level := &menuLevel{menu: menu, selected: -1, box: cview.NewBox()}
+	level.box.SetBorder(true)
+	level.box.SetBackgroundColor(n.colors.Background)
+	level.move(1)
+	n.menus = append(n.menus, level)
+}
+
+// switchMenu moves to menu of next button in direction
+func (n *NavBar) switchMenu(direction int) {
+	index := n.buttonIndex(n.menuButton)
+	if index < 0 {
+		n.CloseMenu()
+		return
+	}
+	index = n.nextButton(index, direction, true)
+	if n.btnMenus[index] != nil {
+		n.openMenu(index)
+	} else {
+		n.CloseMenu()
+		n.setActive(index)
+	}
+}
+
+// selectMenuItem opens submenu of selected item, or closes menus and runs item's action
+func (n *NavBar) selectMenuItem() {
+	level := n.menus[len(n.menus)-1]
+	if level.selected < 0 {
+		return
+	}
+	item := level.menu.items[level.selected]
+	if !item.selectable() {
+		return
+	}
+	if item.submenu != nil {
+		if len(item.submenu.items) > 0 {
+			n.pushMenu(item.submenu)
+		}
+		return
+	}
+	n.CloseMenu()
+	if item.action != nil {
+		item.action()
+	}
+}
+
+// menuInput handles keys for menus. Down opens menu of active button. Return true if event was handled.
+func (n *NavBar) menuInput(event *tcell.EventKey) bool {
+	key := event.Key()
+	if len(n.menus) == 0 {
+		if key == tcell.KeyDown && n.btnMenus[n.btnActiveIndex] != nil {
+			n.openMenu(n.btnActiveIndex)
+			return true
+		}
+		return false
+	}
+
+	level := n.menus[len(n.menus)-1]
+	switch key {
+	case tcell.KeyUp:
+		level.move(-1)
+	case tcell.KeyDown:
+		level.move(1)
+	case tcell.KeyRight:
+		if level.selected >= 0 && level.menu.items[level.selected].submenu != nil {
+			n.selectMenuItem()
+		} else {
+			n.switchMenu(1)
+		}
+	case tcell.KeyLeft:
+		if len(n.menus) > 1 {
+			n.menus = n.menus[:len(n.menus)-1]
+		} else {
+			n.switchMenu(-1)
+		}
+	case tcell.KeyEnter:
+		n.selectMenuItem()
+	case tcell.KeyEscape:
+		n.menus = n.menus[:len(n.menus)-1]
+		if len(n.menus) == 0 {
+			n.CloseMenu()
+		}
+	}
+	return true
+}
+
+// menuMouse handles clicks on menus. Click outside menus closes them. Return true if event was consumed.
+func (n *NavBar) menuMouse(action cview.MouseAction, event *tcell.EventMouse) bool {
+	if len(n.menus) == 0 {
+		return false
+	}
+	x, y := event.Position()
+	for i := len(n.menus) - 1; i >= 0; i-- {
+		level := n.menus[i]
+		if !level.box.InRect(x, y) {
+			continue
+		}
+		if action == cview.MouseLeftClick {
+			_, innerY, _, _ := level.box.GetInnerRect()
+			row := y - innerY
+			if row >= 0 && row < len(level.menu.items) && level.menu.items[row].selectable() {
+				n.menus = n.menus[:i+1]
+				level.selected = row
+				n.selectMenuItem()
+			}
+		}
+		return true
+	}
+	if action == cview.MouseLeftClick {
+		onButton := n.menuButton != nil && n.menuButton.InRect(x, y)
+		n.CloseMenu()
+		return onButton
+	}
+	return false
+}
+
+// menuItemText returns label and hint texts of item
+func menuItemText(item *MenuItem) (string, string) {
+	hint := ""
+	if item.submenu != nil {
+		hint = arrowRight
+	} else if !item.shortcut.IsZero() {
+		hint = cview.Escape(item.shortcut.String())
+	}
+	return cview.Escape(item.label), hint
+}
+
+// drawMenus draws open menus below menu button, and each submenu next to its parent item.
+func (n *NavBar) drawMenus(screen tcell.Screen) {
+	if len(n.menus) == 0 {
+		return
+	}
+	if n.buttonIndex(n.menuButton) < 0 {
+		n.CloseMenu()
+		return
+	}
+	screenWidth, screenHeight := screen.Size()
+	bx, by, _, _ := n.menuButton.GetRect()
+	var parent *menuLevel
+
+	for _, level := range n.menus {
+		width := 0
+		for _, item := range level.menu.items {
+			label, hint := menuItemText(item)
+			itemWidth := cview.TaggedStringWidth(label)
+			if hint != "" {
+				itemWidth += cview.TaggedStringWidth(hint) + 2
+			}
+			width = max(width, itemWidth)
+		}
+		width += 4
+		height := len(level.menu.items) + 2
+
+		var x, y int
+		if parent == nil {
+			x, y = bx, by+1
+			if y+height > screenHeight && by-height >= 0 {
+				y = by - height
+			}
+		} else {
+			px, py, pwidth, _ := parent.box.GetRect()
+			x, y = px+pwidth, py+1+parent.selected
+			if x+width > screenWidth {
+				x = px - width
+			}
+		}
+		x = max(0, min(x, screenWidth-width))
+		y = max(0, min(y, screenHeight-height))
+		level.box.SetRect(x, y, width, height)
+		level.box.Draw(screen)
+		n.drawMenuItems(screen, level)
+		parent = level
+	}
+}
+
+// drawMenuItems draws items inside menu borders
+func (n *NavBar) drawMenuItems(screen tcell.Screen, level *menuLevel) {
+	x, y, width, height := level.box.GetInnerRect()
+	for row, item := range level.menu.items {
+		if row >= height {
+			break
+		}
+		if item.separator {
+			cview.Print(screen, strings.Repeat("─", width), x, y+row, width, cview.AlignLeft, n.colors.Text)
+			continue
+		}
+		background := n.colors.Background
+		text := n.colors.Text
+		hintColor := n.colors.Shortcut
+		if item.disabled {
+			_, text = n.disabledColors()
+			hintColor = text
+		} else if row == level.selected {
+			background = n.colors.ButtonBackgroundFocus
+			text = n.colors.TextFocus
+			hintColor = n.colors.ShortcutFocus
+		}
+		style := tcell.StyleDefault.Background(background)
+		for i := 0; i < width; i++ {
+			screen.SetContent(x+i, y+row, ' ', nil, style)
+		}
+		label, hint := menuItemText(item)
+		hintWidth := 0
+		if hint != "" {
+			hintWidth, _ = cview.Print(screen, hint, x+1, y+row, width-2, cview.AlignRight, hintColor)
+		}
+		cview.Print(screen, label, x+1, y+row, max(0, width-2-hintWidth), cview.AlignLeft, text)
+	}
+}
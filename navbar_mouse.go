@@ -0,0 +1,97 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// buttonMouse handles mouse events on buttons. Click sets button active and selects it, same as Enter.
+// Hovered button is highlighted and wheel moves active button. Return true if event was consumed.
+func (n *NavBar) buttonMouse(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) bool {
+	x, y := event.Position()
+	if !n.grid.InRect(x, y) {
+		n.setHover(-1)
+		return false
+	}
+	index := n.buttonAt(x, y)
+	switch action {
+	case cview.MouseMove:
+		n.setHover(index)
+		return true
+	case cview.MouseLeftClick, cview.MouseLeftDoubleClick:
+		// Quick second click is reported as double click
+		if index < 0 {
+			return false
+		}
+		if n.buttonSelectable(index) {
+			setFocus(n)
+			n.setActive(index)
+			n.activateButton(index)
+		}
+		return true
+	case cview.MouseScrollUp, cview.MouseScrollLeft:
+		setFocus(n)
+		n.moveActive(n.nextButton(n.btnActiveIndex, -1, true))
+		return true
+	case cview.MouseScrollDown, cview.MouseScrollRight:
+		setFocus(n)
+		n.moveActive(n.nextButton(n.btnActiveIndex, 1, true))
+		return true
+	}
+	// Don't let buttons take focus from navbar
+	return index >= 0
+}
+
+// buttonAt returns index of visible button at position, or -1
+func (n *NavBar) buttonAt(x, y int) int {
+	o := n.overflow
+	for i, button := range n.buttons {
+		if n.hidden[button] || o.overflowing && (i < o.first || i > o.last) {
+			continue
+		}
+		if button.InRect(x, y) {
+			return i
+		}
+	}
+	return -1
+}
+
+// setHover highlights button at index, or none if index is -1
+func (n *NavBar) setHover(index int) {
+	var hover *cview.Button
+	if index >= 0 && n.buttonSelectable(index) {
+		hover = n.buttons[index]
+	}
+	if hover == n.hover {
+		return
+	}
+	n.hover = hover
+	n.styleButtons()
+}
+
+// moveActive sets button at index active. In tab mode its tab is selected too.
+func (n *NavBar) moveActive(index int) {
+	if index == n.btnActiveIndex {
+		return
+	}
+	n.setActive(index)
+	if n.pages != nil {
+		n.activateButton(index)
+	}
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// overflowButtonWidth is width of the button that opens list of hidden buttons
+const overflowButtonWidth = 3
+
+// navBarOverflow is state of buttons that do not fit in navbar.
+// When buttons overflow, only buttons first...last are shown with their full width and rest of them
+// are available from a dropdown list.
+type navBarOverflow struct {
+	overflowing bool
+	first       int
+	last        int
+	width       int
+	dirty       bool
+
+	button *cview.Button
+	list   *cview.List
+	// items are button indices of list items
+	items []int
+}
+
+// IsOverflowing returns true if all buttons do not fit in navbar. Hidden buttons can be selected from
+// dropdown list, that is opened with '»' button or Down key.
+func (n *NavBar) IsOverflowing() bool {
+	return n.overflow.overflowing
+}
+
+// layoutButtons puts buttons that fit to grid. Active button is always kept visible.
+func (n *NavBar) layoutButtons() {
+	o := n.overflow
+	_, _, width, _ := n.grid.GetRect()
+	active := n.btnActiveIndex
+	if !o.dirty && width == o.width && (!o.overflowing || active >= o.first && active <= o.last) {
+		return
+	}
+	o.dirty = false
+	o.width = width
+
+	if width <= 0 || n.buttonsFit(width) {
+		o.overflowing = false
+		o.first = 0
+		o.last = len(n.buttons) - 1
+		n.closeOverflow()
+		n.fillGrid()
+		return
+	}
+	o.overflowing = true
+	n.scrollButtons(width - overflowButtonWidth)
+	n.fillOverflowGrid()
+}
+
+// buttonsFit returns true if every label fits in equally sized buttons
+func (n *NavBar) buttonsFit(width int) bool {
+	visible := n.visibleButtons()
+	if len(visible) == 0 {
+		return true
+	}
+	share := width * 2 / (len(visible)*3 + 2)
+	for _, index := range visible {
+		if cview.TaggedStringWidth(n.buttons[index].GetLabel()) > share {
+			return false
+		}
+	}
+	return true
+}
+
+// buttonWidth returns width of button that shows its full label
+func (n *NavBar) buttonWidth(index int) int {
+	return cview.TaggedStringWidth(n.buttons[index].GetLabel()) + 2
+}
+
+// buttonsWidth returns total width of visible buttons first...last, including spaces between them
+func (n *NavBar) buttonsWidth(first, last int) int {
+	width := 0
+	count := 0
+	for i := first; i <= last; i++ {
+		if n.hidden[n.buttons[i]] {
+			continue
+		}
+		width += n.buttonWidth(i)
+		count++
+	}
+	return width + max(0, count-1)
+}
+
+// scrollButtons sets visible buttons so that active button is shown and as many buttons as fit in width.
+// Visible buttons are scrolled only as much as needed.
+func (n *NavBar) scrollButtons(width int) {
+	o := n.overflow
+	active := n.btnActiveIndex
+	first := max(0, min(o.first, active))
+	for first < active && n.buttonsWidth(first, active) > width {
+		first++
+	}
+	last := active
+	for last < len(n.buttons)-1 && n.buttonsWidth(first, last+1) <= width {
+		last++
+	}
+	for first > 0 && n.buttonsWidth(first-1, last) <= width {
+		first--
+	}
+	o.first = first
+	o.last = last
+}
+
+// fillOverflowGrid puts visible buttons to grid followed by overflow button
+func (n *NavBar) fillOverflowGrid() {
+	o := n.overflow
+	if o.button == nil {
+		o.button = cview.NewButton("»")
+		o.button.SetBorder(false)
+		o.button.SetBackgroundColor(n.colors.ButtonBackground)
+		o.button.SetLabelColor(n.colors.Shortcut)
+	}
+
+	n.grid.Clear()
+	widths := []int{}
+	for i := o.first; i <= o.last; i++ {
+		if n.hidden[n.buttons[i]] {
+			continue
+		}
+		if len(widths) > 0 {
+			widths = append(widths, 1)
+		}
+		n.grid.AddItem(n.buttons[i], 0, len(widths), 1, 1, 1, 0, false)
+		widths = append(widths, n.buttonWidth(i))
+	}
+	widths = append(widths, -1)
+	n.grid.AddItem(o.button, 0, len(widths), 1, 1, 1, 0, false)
+	widths = append(widths, overflowButtonWidth)
+	n.grid.SetColumns(widths...)
+}
+
+// openOverflow opens dropdown list of hidden buttons
+func (n *NavBar) openOverflow() {
+	o := n.overflow
+	if !o.overflowing {
+		return
+	}
+	o.items = []int{}
+	o.list = cview.NewList()
+	o.list.ShowSecondaryText(false)
+	o.list.SetWrapAround(true)
+	o.list.SetBorder(true)
+	o.list.SetBackgroundColor(n.colors.Background)
+	o.list.SetMainTextColor(n.colors.Text)
+	o.list.SetSelectedBackgroundColor(n.colors.ButtonBackgroundFocus)
+	o.list.SetSelectedTextColor(n.colors.TextFocus)
+	for i, button := range n.buttons {
+		if i >= o.first && i <= o.last || n.hidden[button] {
+			continue
+		}
+		o.items = append(o.items, i)
+		o.list.AddItem(button.GetLabel(), "", 0, nil)
+		o.list.SetItemEnabled(len(o.items)-1, !n.disabled[button])
+	}
+	o.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		button := o.items[index]
+		n.closeOverflow()
+		n.setActive(button)
+		n.activateButton(button)
+	})
+	o.list.SetDoneFunc(n.closeOverflow)
+	o.list.Focus(nil)
+}
+
+// closeOverflow closes dropdown list, if open
+func (n *NavBar) closeOverflow() {
+	n.overflow.list = nil
+	n.overflow.items = nil
+}
+
+// overflowInput handles keys for dropdown list. Return true if event was handled.
+func (n *NavBar) overflowInput(event *tcell.EventKey) bool {
+	o := n.overflow
+	if o.list != nil {
+		o.list.InputHandler()(event, func(p cview.Primitive) {})
+		return true
+	}
+	if o.overflowing && event.Key() == tcell.KeyDown {
+		n.openOverflow()
+		return true
+	}
+	return false
+}
+
+// overflowMouse handles mouse events for overflow button and dropdown list.
+// Return true if event was consumed.
+func (n *NavBar) overflowMouse(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) bool {
+	o := n.overflow
+	if !o.overflowing {
+		return false
+	}
+	x, y := event.Position()
+	if o.list != nil {
+		if o.list.InRect(x, y) {
+			o.list.MouseHandler()(action, event, func(p cview.Primitive) {})
+			return true
+		}
+		if action == cview.MouseLeftClick {
+			// Click outside closes list
+			n.closeOverflow()
+			return o.button.InRect(x, y)
+		}
+		return false
+	}
+	if action == cview.MouseLeftClick && o.button.InRect(x, y) {
+		setFocus(n)
+		n.openOverflow()
+		return true
+	}
+	return false
+}
+
+// drawOverflow draws dropdown list below overflow button, or above it if there is no space below.
+func (n *NavBar) drawOverflow(screen tcell.Screen) {
+	o := n.overflow
+	if o.list == nil {
+		return
+	}
+	width := 0
+	for i := 0; i < o.list.GetItemCount(); i++ {
+		text, _ := o.list.GetItemText(i)
+		width = max(width, cview.TaggedStringWidth(text))
+	}
+	width += 2
+	height := o.list.GetItemCount() + 2
+
+	bx, by, bwidth, _ := o.button.GetRect()
+	_, screenHeight := screen.Size()
+	x := max(0, bx+bwidth-width)
+	y := by + 1
+	if y+height > screenHeight && by-height >= 0 {
+		y = by - height
+	}
+	height = min(height, screenHeight-y)
+	o.list.SetRect(x, y, width, height)
+	o.list.Draw(screen)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"strings"
+	"unicode"
+)
+
+// Shortcut is a key combination that activates a button. For runes Key is tcell.KeyRune.
+type Shortcut struct {
+	Key       tcell.Key
+	Rune      rune
+	Modifiers tcell.ModMask
+}
+
+// KeyShortcut creates shortcut for special key, e.g. tcell.KeyF1 or tcell.KeyCtrlS.
+func KeyShortcut(key tcell.Key) Shortcut {
+	return Shortcut{Key: key}
+}
+
+// RuneShortcut creates shortcut for rune with optional modifiers, e.g. 'q' or Alt+1.
+func RuneShortcut(r rune, modifiers tcell.ModMask) Shortcut {
+	return Shortcut{Key: tcell.KeyRune, Rune: r, Modifiers: modifiers}
+}
+
+// IsZero returns true if shortcut has no key.
+func (s Shortcut) IsZero() bool {
+	return s.Key == tcell.KeyNUL && s.Rune == 0
+}
+
+// String returns human readable name of shortcut, e.g. 'F1', 'q' or 'Alt-1'. Equal shortcuts have
+// same name, e.g. Ctrl+'s' and tcell.KeyCtrlS are both 'Ctrl-S'.
+func (s Shortcut) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	s = s.normalize()
+	var name string
+	if s.Key == tcell.KeyRune {
+		name = string(s.Rune)
+		if s.Rune == ' ' {
+			name = "Space"
+		}
+	} else if keyName, ok := tcell.KeyNames[s.Key]; ok {
+		name = keyName
+	} else {
+		name = fmt.Sprintf("Key[%d]", s.Key)
+	}
+
+	mods := s.modifiers()
+	prefix := ""
+	if mods&tcell.ModCtrl != 0 && !strings.HasPrefix(name, "Ctrl-") {
+		prefix += "Ctrl-"
+	}
+	if mods&tcell.ModAlt != 0 {
+		prefix += "Alt-"
+	}
+	if mods&tcell.ModMeta != 0 {
+		prefix += "Meta-"
+	}
+	if mods&tcell.ModShift != 0 {
+		prefix += "Shift-"
+	}
+	return prefix + name
+}
+
+// Matches returns true if event is of this shortcut.
+func (s Shortcut) Matches(event *tcell.EventKey) bool {
+	if s.IsZero() {
+		return false
+	}
+	other := Shortcut{Key: event.Key(), Modifiers: event.Modifiers()}
+	if other.Key == tcell.KeyRune {
+		other.Rune = event.Rune()
+	}
+	return s.normalize() == other.normalize()
+}
+
+// modifiers returns modifiers that are significant for shortcut.
+func (s Shortcut) modifiers() tcell.ModMask {
+	mods := s.Modifiers
+	if s.Key == tcell.KeyRune {
+		// Shift is already part of rune
+		mods &^= tcell.ModShift
+	}
+	if isControlKey(s.Key) {
+		// Control keys always have ctrl
+		mods &^= tcell.ModCtrl
+	}
+	return mods
+}
+
+// normalize returns shortcut that can be compared with other shortcuts
+func (s Shortcut) normalize() Shortcut {
+	n := Shortcut{Key: s.Key, Modifiers: s.modifiers()}
+	if s.Key == tcell.KeyRune {
+		n.Rune = s.Rune
+		lower := unicode.ToLower(s.Rune)
+		if n.Modifiers&tcell.ModCtrl != 0 && lower >= 'a' && lower <= 'z' {
+			// Terminal sends Ctrl+letter as control key
+			n.Key = tcell.KeyCtrlA + tcell.Key(lower-'a')
+			n.Rune = 0
+			n.Modifiers &^= tcell.ModCtrl
+		}
+	}
+	return n
+}
+
+// isControlKey returns true if key is an ascii control key, e.g. Ctrl-A
+func isControlKey(key tcell.Key) bool {
+	return key < tcell.Key(' ') || key == tcell.KeyDEL
+}
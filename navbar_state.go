@@ -0,0 +1,148 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// SetButtonDisabled sets whether button at index can be selected. Disabled button is drawn with
+// disabled colors, and it is skipped when moving with arrows. Its shortcut and mouse clicks are ignored.
+func (n *NavBar) SetButtonDisabled(index int, disabled bool) {
+	if index < 0 || index >= len(n.buttons) {
+		return
+	}
+	if disabled {
+		n.disabled[n.buttons[index]] = true
+	} else {
+		delete(n.disabled, n.buttons[index])
+	}
+	n.styleButton(index)
+	n.leaveButton(index)
+}
+
+// IsButtonDisabled returns true if button at index is disabled.
+func (n *NavBar) IsButtonDisabled(index int) bool {
+	return index >= 0 && index < len(n.buttons) && n.disabled[n.buttons[index]]
+}
+
+// SetButtonHidden sets whether button at index is hidden. Hidden button is not drawn and it cannot be selected.
+func (n *NavBar) SetButtonHidden(index int, hidden bool) {
+	if index < 0 || index >= len(n.buttons) {
+		return
+	}
+	if hidden {
+		n.hidden[n.buttons[index]] = true
+	} else {
+		delete(n.hidden, n.buttons[index])
+	}
+	n.leaveButton(index)
+	n.rebuildGrid()
+}
+
+// IsButtonHidden returns true if button at index is hidden.
+func (n *NavBar) IsButtonHidden(index int) bool {
+	return index >= 0 && index < len(n.buttons) && n.hidden[n.buttons[index]]
+}
+
+// buttonSelectable returns true if button at index is visible and enabled
+func (n *NavBar) buttonSelectable(index int) bool {
+	button := n.buttons[index]
+	return !n.disabled[button] && !n.hidden[button]
+}
+
+// visibleButtons returns indices of buttons that are not hidden
+func (n *NavBar) visibleButtons() []int {
+	visible := []int{}
+	for i, button := range n.buttons {
+		if !n.hidden[button] {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+// nextButton returns index of next selectable button from index in direction. If there is none,
+// index is returned.
+func (n *NavBar) nextButton(index, direction int, wrap bool) int {
+	count := len(n.buttons)
+	for i := 1; i < count; i++ {
+		next := index + direction*i
+		if wrap {
+			next = (next%count + count) % count
+		} else if next < 0 || next >= count {
+			break
+		}
+		if n.buttonSelectable(next) {
+			return next
+		}
+	}
+	return index
+}
+
+// leaveButton moves active button away from button at index, if it became unselectable
+func (n *NavBar) leaveButton(index int) {
+	if index != n.btnActiveIndex || n.buttonSelectable(index) {
+		return
+	}
+	next := n.nextButton(index, 1, false)
+	if next == index {
+		next = n.nextButton(index, -1, false)
+	}
+	n.setActive(next)
+	n.styleButtons()
+}
+
+// styleButtons sets colors and label of every button
+func (n *NavBar) styleButtons() {
+	for i := range n.buttons {
+		n.styleButton(i)
+	}
+}
+
+// disabledColors returns colors for disabled buttons and menu items. If colors are tcell.ColorDefault,
+// normal button background and dimmed text are used.
+func (n *NavBar) disabledColors() (background, text tcell.Color) {
+	background, text = n.colors.ButtonBackgroundDisabled, n.colors.TextDisabled
+	if background == tcell.ColorDefault {
+		background = n.colors.ButtonBackground
+	}
+	if text == tcell.ColorDefault {
+		text = cview.Styles.TertiaryTextColor
+	}
+	return
+}
+
+// styleButton sets colors and label of button at index. Disabled button has disabled colors and
+// in tab mode active tab and hovered button have focus colors.
+func (n *NavBar) styleButton(index int) {
+	button := n.buttons[index]
+	background, backgroundFocus := n.colors.ButtonBackground, n.colors.ButtonBackgroundFocus
+	text, textFocus := n.colors.Text, n.colors.TextFocus
+	if n.disabled[button] {
+		background, text = n.disabledColors()
+		backgroundFocus, textFocus = background, text
+	} else if n.pages != nil && index == n.btnActiveIndex || button == n.hover {
+		background, text = backgroundFocus, textFocus
+	}
+	button.SetBackgroundColor(background)
+	button.SetBackgroundColorActivated(backgroundFocus)
+	button.SetLabelColor(text)
+	button.SetLabelColorActivated(textFocus)
+	button.SetLabel(n.buttonLabel(index))
+}
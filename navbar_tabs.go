@@ -0,0 +1,139 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// SetPages enables tab mode. Each tab button is tied to a page in pages, see AddTab. Selecting tab
+// switches to its page and active tab stays highlighted when navbar is not focused. In addition to
+// button shortcuts, tabs can be switched with Ctrl+PgUp / Ctrl+PgDn and number keys 1-9.
+// If page is switched directly with pages, active tab is updated on next draw. Nil pages disables tab mode.
+func (n *NavBar) SetPages(pages *cview.Pages) {
+	n.pages = pages
+	if pages == nil {
+		n.styleButtons()
+	} else {
+		n.updateTabs()
+	}
+}
+
+// AddTab adds a new button to right side of existing buttons and ties it to page. If shortcut is already
+// used by another button, error is returned and button is not added.
+func (n *NavBar) AddTab(button *cview.Button, page string, shortcut Shortcut) error {
+	err := n.InsertButton(len(n.buttons), button, shortcut)
+	if err != nil {
+		return err
+	}
+	n.btnPages[len(n.buttons)-1] = page
+	return nil
+}
+
+// SetButtonPage ties button at index to page.
+func (n *NavBar) SetButtonPage(index int, page string) {
+	if index < 0 || index >= len(n.buttons) {
+		return
+	}
+	n.btnPages[index] = page
+}
+
+// GetActiveTab returns index of active tab, or -1 if tab mode is not enabled.
+func (n *NavBar) GetActiveTab() int {
+	if n.pages == nil || len(n.buttons) == 0 {
+		return -1
+	}
+	return n.btnActiveIndex
+}
+
+// selectTab sets button at index active and switches to its page
+func (n *NavBar) selectTab(index int) {
+	n.setActive(index)
+	page := n.btnPages[index]
+	if page != "" && n.pages.HasPage(page) {
+		n.pages.SwitchToPage(page)
+	}
+	n.styleButtons()
+}
+
+// setActive changes active button and moves focus to it
+func (n *NavBar) setActive(index int) {
+	if index == n.btnActiveIndex {
+		return
+	}
+	n.buttons[n.btnActiveIndex].Blur()
+	n.btnActiveIndex = index
+	if n.hasFocus {
+		n.buttons[index].Focus(nil)
+	}
+}
+
+// tabInput handles keys for switching tabs. Return true if event was handled.
+func (n *NavBar) tabInput(event *tcell.EventKey) bool {
+	index := -1
+	switch event.Key() {
+	case tcell.KeyPgUp:
+		if event.Modifiers()&tcell.ModCtrl != 0 {
+			index = n.nextButton(n.btnActiveIndex, -1, true)
+		}
+	case tcell.KeyPgDn:
+		if event.Modifiers()&tcell.ModCtrl != 0 {
+			index = n.nextButton(n.btnActiveIndex, 1, true)
+		}
+	case tcell.KeyRune:
+		r := event.Rune()
+		shortcut := RuneShortcut(r, event.Modifiers())
+		if r >= '1' && r <= '9' && shortcut.modifiers() == 0 && n.shortcutIndex(shortcut) < 0 {
+			index = n.selectableButton(int(r - '1'))
+		}
+	}
+	if index < 0 || index >= len(n.buttons) {
+		return false
+	}
+	n.activateButton(index)
+	return true
+}
+
+// selectableButton returns index of nth visible and enabled button, or -1
+func (n *NavBar) selectableButton(nth int) int {
+	for i := range n.buttons {
+		if !n.buttonSelectable(i) {
+			continue
+		}
+		if nth == 0 {
+			return i
+		}
+		nth--
+	}
+	return -1
+}
+
+// updateTabs sets tab of current page active, in case page was switched outside navbar
+func (n *NavBar) updateTabs() {
+	if n.pages == nil {
+		return
+	}
+	front, _ := n.pages.GetFrontPage()
+	for i, page := range n.btnPages {
+		if page != "" && page == front {
+			n.setActive(i)
+			break
+		}
+	}
+	n.styleButtons()
+}
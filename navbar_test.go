@@ -0,0 +1,542 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testNavBarColors() *NavBarColors {
+	return &NavBarColors{
+		Background:               tcell.Color235,
+		BackgroundFocus:          tcell.Color235,
+		ButtonBackground:         tcell.Color235,
+		ButtonBackgroundFocus:    tcell.Color23,
+		Text:                     tcell.Color252,
+		TextFocus:                tcell.Color253,
+		Shortcut:                 tcell.Color214,
+		ShortcutFocus:            tcell.Color214,
+		ButtonBackgroundDisabled: tcell.Color235,
+		TextDisabled:             tcell.Color242,
+	}
+}
+
+func TestShortcut(t *testing.T) {
+	tests := []struct {
+		name     string
+		shortcut Shortcut
+		event    *tcell.EventKey
+		text     string
+		matches  bool
+	}{
+		{"function key", KeyShortcut(tcell.KeyF1), tcell.NewEventKey(tcell.KeyF1, 0, tcell.ModNone), "F1", true},
+		{"function key with alt", KeyShortcut(tcell.KeyF1), tcell.NewEventKey(tcell.KeyF1, 0, tcell.ModAlt), "F1", false},
+		{"control key", KeyShortcut(tcell.KeyCtrlS), tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl), "Ctrl-S", true},
+		{"rune", RuneShortcut('q', tcell.ModNone), tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone), "q", true},
+		{"other rune", RuneShortcut('q', tcell.ModNone), tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone), "q", false},
+		{"shifted rune", RuneShortcut('?', tcell.ModNone), tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModShift), "?", true},
+		{"alt rune", RuneShortcut('1', tcell.ModAlt), tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModAlt), "Alt-1", true},
+		{"alt rune without alt", RuneShortcut('1', tcell.ModAlt), tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone), "Alt-1", false},
+		{"ctrl letter", RuneShortcut('s', tcell.ModCtrl), tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl), "Ctrl-S", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shortcut.String(); got != tt.text {
+				t.Errorf("String() = %v, want %v", got, tt.text)
+			}
+			if got := tt.shortcut.Matches(tt.event); got != tt.matches {
+				t.Errorf("Matches() = %v, want %v", got, tt.matches)
+			}
+		})
+	}
+}
+
+func TestNavBar_AddButtonShortcut(t *testing.T) {
+	var done []string
+	nav := NewNavBar(testNavBarColors(), func(label string) {
+		done = append(done, label)
+	})
+	if err := nav.AddButtonShortcut(cview.NewButton("quit"), RuneShortcut('q', tcell.ModNone)); err != nil {
+		t.Fatalf("add button: %v", err)
+	}
+	if err := nav.AddButtonShortcut(cview.NewButton("help"), RuneShortcut('?', tcell.ModNone)); err != nil {
+		t.Fatalf("add button: %v", err)
+	}
+	if err := nav.AddButtonShortcut(cview.NewButton("queue"), RuneShortcut('q', tcell.ModNone)); err == nil {
+		t.Errorf("conflicting shortcut was accepted")
+	}
+	nav.AddButton(cview.NewButton("save"), tcell.KeyCtrlS)
+	nav.AddButton(cview.NewButton("store"), tcell.KeyCtrlS)
+	if err := nav.AddButtonShortcut(cview.NewButton("sync"), RuneShortcut('s', tcell.ModCtrl)); err == nil {
+		t.Errorf("conflicting ctrl shortcut was accepted")
+	}
+	if len(nav.buttons) != 3 {
+		t.Errorf("conflicting button was added")
+	}
+
+	nav.InputHandler()(tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModNone), func(p cview.Primitive) {})
+	if len(done) != 1 || done[0] != "help" {
+		t.Errorf("shortcut did not activate button: %v", done)
+	}
+}
+
+func TestNavBar_modifyButtons(t *testing.T) {
+	nav := NewNavBar(testNavBarColors(), nil)
+	for i, label := range []string{"a", "b", "c"} {
+		nav.AddButton(cview.NewButton(label), tcell.KeyF1+tcell.Key(i))
+	}
+	labels := func() []string {
+		out := []string{}
+		for i := 0; i < nav.GetButtonCount(); i++ {
+			out = append(out, nav.GetButtonLabel(i))
+		}
+		return out
+	}
+
+	nav.btnActiveIndex = 1
+	if err := nav.InsertButton(0, cview.NewButton("x"), RuneShortcut('x', tcell.ModNone)); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if got := labels(); !reflect.DeepEqual(got, []string{"x", "a", "b", "c"}) || nav.btnActiveIndex != 2 {
+		t.Errorf("insert: got %v, active %d", got, nav.btnActiveIndex)
+	}
+
+	nav.MoveButton(2, 0)
+	if got := labels(); !reflect.DeepEqual(got, []string{"b", "x", "a", "c"}) || nav.btnActiveIndex != 0 {
+		t.Errorf("move: got %v, active %d", got, nav.btnActiveIndex)
+	}
+
+	nav.SetButtonLabel(0, "renamed")
+	if got := nav.buttons[0].GetLabel(); got != "[#ffaf00]F2[-] renamed" {
+		t.Errorf("button label: got %s", got)
+	}
+
+	nav.RemoveButton(0)
+	nav.RemoveButton(2)
+	if got := labels(); !reflect.DeepEqual(got, []string{"x", "a"}) || nav.btnActiveIndex != 0 {
+		t.Errorf("remove: got %v, active %d", got, nav.btnActiveIndex)
+	}
+	nav.RemoveButton(0)
+	nav.RemoveButton(0)
+	nav.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(p cview.Primitive) {})
+}
+
+func TestNavBar_tabs(t *testing.T) {
+	pages := cview.NewPages()
+	nav := NewNavBar(testNavBarColors(), nil)
+	nav.SetPages(pages)
+	for i, name := range []string{"a", "b", "c"} {
+		pages.AddPage(name, cview.NewBox(), true, i == 0)
+		if err := nav.AddTab(cview.NewButton(name), name, KeyShortcut(tcell.KeyF1+tcell.Key(i))); err != nil {
+			t.Fatalf("add tab: %v", err)
+		}
+	}
+	input := func(key tcell.Key, r rune, mods tcell.ModMask) {
+		nav.InputHandler()(tcell.NewEventKey(key, r, mods), func(p cview.Primitive) {})
+	}
+	front := func() string {
+		name, _ := pages.GetFrontPage()
+		return name
+	}
+
+	tests := []struct {
+		name   string
+		key    tcell.Key
+		r      rune
+		mods   tcell.ModMask
+		active int
+	}{
+		{"shortcut", tcell.KeyF3, 0, tcell.ModNone, 2},
+		{"next wraps", tcell.KeyPgDn, 0, tcell.ModCtrl, 0},
+		{"previous wraps", tcell.KeyPgUp, 0, tcell.ModCtrl, 2},
+		{"number", tcell.KeyRune, '2', tcell.ModNone, 1},
+		{"number out of range", tcell.KeyRune, '9', tcell.ModNone, 1},
+		{"pgdn without ctrl", tcell.KeyPgDn, 0, tcell.ModNone, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input(tt.key, tt.r, tt.mods)
+			if nav.GetActiveTab() != tt.active || front() != nav.btnPages[tt.active] {
+				t.Errorf("active tab %d, page %s, want %d", nav.GetActiveTab(), front(), tt.active)
+			}
+		})
+	}
+
+	nav.SetButtonHidden(0, true)
+	input(tcell.KeyRune, '2', tcell.ModNone)
+	if nav.GetActiveTab() != 2 {
+		t.Errorf("number with hidden tab: active tab %d, want 2", nav.GetActiveTab())
+	}
+	nav.SetButtonHidden(0, false)
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("init screen: %v", err)
+	}
+	screen.SetSize(60, 1)
+	nav.SetRect(0, 0, 60, 1)
+
+	nav.Blur()
+	pages.SwitchToPage("c")
+	nav.Draw(screen)
+	if nav.GetActiveTab() != 2 {
+		t.Errorf("page switch did not update tab: %d", nav.GetActiveTab())
+	}
+	x, y, _, _ := nav.buttons[2].GetRect()
+	_, _, style, _ := screen.GetContent(x, y)
+	if _, bg, _ := style.Decompose(); bg != nav.colors.ButtonBackgroundFocus {
+		t.Errorf("active tab background %v, want %v", bg, nav.colors.ButtonBackgroundFocus)
+	}
+}
+
+func TestNavBar_overflow(t *testing.T) {
+	var done []string
+	nav := NewNavBar(testNavBarColors(), func(label string) {
+		done = append(done, label)
+	})
+	for _, label := range []string{"first", "second", "third", "fourth", "fifth"} {
+		if err := nav.AddButtonShortcut(cview.NewButton(label), Shortcut{}); err != nil {
+			t.Fatalf("add button: %v", err)
+		}
+	}
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("init screen: %v", err)
+	}
+	screen.SetSize(30, 10)
+	nav.SetRect(0, 0, 30, 1)
+	nav.Focus(nil)
+	input := func(key tcell.Key) {
+		nav.InputHandler()(tcell.NewEventKey(key, 0, tcell.ModNone), func(p cview.Primitive) {})
+	}
+
+	nav.Draw(screen)
+	if !nav.IsOverflowing() {
+		t.Fatalf("navbar is not overflowing")
+	}
+	if nav.overflow.first != 0 || nav.overflow.last != 2 {
+		t.Errorf("visible buttons %d-%d, want 0-2", nav.overflow.first, nav.overflow.last)
+	}
+
+	for i := 0; i < 4; i++ {
+		input(tcell.KeyRight)
+	}
+	nav.Draw(screen)
+	if nav.overflow.first != 2 || nav.overflow.last != 4 {
+		t.Errorf("active button not in view: visible %d-%d", nav.overflow.first, nav.overflow.last)
+	}
+
+	input(tcell.KeyDown)
+	if nav.overflow.list == nil || !reflect.DeepEqual(nav.overflow.items, []int{0, 1}) {
+		t.Fatalf("hidden buttons: %v", nav.overflow.items)
+	}
+	nav.Draw(screen)
+	input(tcell.KeyDown)
+	input(tcell.KeyEnter)
+	if nav.overflow.list != nil {
+		t.Errorf("list not closed")
+	}
+	if nav.btnActiveIndex != 1 || !reflect.DeepEqual(done, []string{"second"}) {
+		t.Errorf("selected button %d, done %v", nav.btnActiveIndex, done)
+	}
+	nav.Draw(screen)
+	if nav.overflow.first != 1 {
+		t.Errorf("selected button not in view: visible %d-%d", nav.overflow.first, nav.overflow.last)
+	}
+
+	nav.SetRect(0, 0, 80, 1)
+	nav.Draw(screen)
+	if nav.IsOverflowing() {
+		t.Errorf("navbar overflowing with enough space")
+	}
+}
+
+func TestNavBar_menu(t *testing.T) {
+	var done, actions []string
+	nav := NewNavBar(testNavBarColors(), func(label string) {
+		done = append(done, label)
+	})
+	action := func(name string) func() {
+		return func() { actions = append(actions, name) }
+	}
+	recent := NewMenu()
+	recent.AddItem("a.txt", Shortcut{}, action("a.txt"))
+	recent.AddItem("b.txt", Shortcut{}, action("b.txt"))
+	file := NewMenu()
+	file.AddItem("New", RuneShortcut('n', tcell.ModCtrl), action("new"))
+	file.AddSeparator()
+	file.AddSubmenu("Recent", recent)
+	file.AddItem("Quit", Shortcut{}, action("quit")).SetDisabled(true)
+	edit := NewMenu()
+	edit.AddItem("Copy", Shortcut{}, action("copy"))
+
+	if err := nav.AddMenuButton(cview.NewButton("File"), KeyShortcut(tcell.KeyF1), file); err != nil {
+		t.Fatalf("add button: %v", err)
+	}
+	if err := nav.AddMenuButton(cview.NewButton("Edit"), KeyShortcut(tcell.KeyF2), edit); err != nil {
+		t.Fatalf("add button: %v", err)
+	}
+	nav.AddButton(cview.NewButton("Help"), tcell.KeyF3)
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("init screen: %v", err)
+	}
+	screen.SetSize(60, 10)
+	nav.SetRect(0, 0, 60, 1)
+	nav.Focus(nil)
+	input := func(keys ...tcell.Key) {
+		for _, key := range keys {
+			nav.InputHandler()(tcell.NewEventKey(key, 0, tcell.ModNone), func(p cview.Primitive) {})
+			nav.Draw(screen)
+		}
+	}
+	selected := func() (int, int) {
+		if !nav.IsMenuOpen() {
+			return -1, -1
+		}
+		return len(nav.menus), nav.menus[len(nav.menus)-1].selected
+	}
+
+	tests := []struct {
+		name     string
+		keys     []tcell.Key
+		depth    int
+		selected int
+		actions  []string
+	}{
+		{"open", []tcell.Key{tcell.KeyEnter}, 1, 0, nil},
+		{"skip separator", []tcell.Key{tcell.KeyDown}, 1, 2, nil},
+		{"skip disabled", []tcell.Key{tcell.KeyDown}, 1, 0, nil},
+		{"submenu", []tcell.Key{tcell.KeyUp, tcell.KeyRight, tcell.KeyDown}, 2, 1, nil},
+		{"close submenu", []tcell.Key{tcell.KeyLeft}, 1, 2, nil},
+		{"select from submenu", []tcell.Key{tcell.KeyRight, tcell.KeyEnter}, -1, -1, []string{"a.txt"}},
+		{"next menu", []tcell.Key{tcell.KeyF1, tcell.KeyRight}, 1, 0, []string{"a.txt"}},
+		{"escape", []tcell.Key{tcell.KeyEscape}, -1, -1, []string{"a.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input(tt.keys...)
+			depth, index := selected()
+			if depth != tt.depth || index != tt.selected {
+				t.Errorf("menu depth %d, selected %d, want %d, %d", depth, index, tt.depth, tt.selected)
+			}
+			if !reflect.DeepEqual(actions, tt.actions) {
+				t.Errorf("actions %v, want %v", actions, tt.actions)
+			}
+		})
+	}
+	if nav.btnActiveIndex != 1 || done != nil {
+		t.Errorf("active button %d, done %v", nav.btnActiveIndex, done)
+	}
+
+	input(tcell.KeyDown)
+	click := tcell.NewEventMouse(30, 5, tcell.Button1, tcell.ModNone)
+	nav.MouseHandler()(cview.MouseLeftClick, click, func(p cview.Primitive) {})
+	if nav.IsMenuOpen() {
+		t.Errorf("click outside did not close menu")
+	}
+}
+
+func TestNavBar_badges(t *testing.T) {
+	var done []string
+	nav := NewNavBar(testNavBarColors(), func(label string) {
+		done = append(done, label)
+	})
+	downloads := cview.NewButton("Downloads")
+	nav.AddButton(downloads, tcell.KeyF1)
+	redraws := make(chan bool, 10)
+	nav.SetRedrawFunc(func() {
+		redraws <- true
+	})
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("init screen: %v", err)
+	}
+	screen.SetSize(40, 1)
+	nav.SetRect(0, 0, 40, 1)
+
+	go nav.SetBadgeCount(downloads, 3, tcell.ColorRed)
+	<-redraws
+	nav.Draw(screen)
+	if got := downloads.GetLabel(); got != "[#ffaf00]F1[-] Downloads [#ff0000](3)[-]" {
+		t.Errorf("label with badge: %s", got)
+	}
+
+	nav.InputHandler()(tcell.NewEventKey(tcell.KeyF1, 0, tcell.ModNone), func(p cview.Primitive) {})
+	if !reflect.DeepEqual(done, []string{"Downloads"}) {
+		t.Errorf("done label: %v", done)
+	}
+
+	nav.SetBadgeCount(downloads, 0, tcell.ColorRed)
+	nav.Draw(screen)
+	if got := downloads.GetLabel(); got != "[#ffaf00]F1[-] Downloads" {
+		t.Errorf("label without badge: %s", got)
+	}
+}
+
+func TestNavBar_disabledButtons(t *testing.T) {
+	var done []string
+	nav := NewNavBar(testNavBarColors(), func(label string) {
+		done = append(done, label)
+	})
+	for i, label := range []string{"a", "b", "c", "d"} {
+		nav.AddButton(cview.NewButton(label), tcell.KeyF1+tcell.Key(i))
+	}
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("init screen: %v", err)
+	}
+	screen.SetSize(60, 1)
+	nav.SetRect(0, 0, 60, 1)
+	nav.Focus(nil)
+	input := func(key tcell.Key) {
+		nav.InputHandler()(tcell.NewEventKey(key, 0, tcell.ModNone), func(p cview.Primitive) {})
+	}
+
+	nav.SetButtonDisabled(1, true)
+	nav.SetButtonHidden(2, true)
+	nav.Draw(screen)
+	if got := nav.buttons[1].GetLabel(); got != "[#6c6c6c]F2[-] b" {
+		t.Errorf("disabled label: %s", got)
+	}
+	screen.Show()
+	if row := screenRow(screen, 0); strings.Contains(row, "F3") || !strings.Contains(row, "F4") {
+		t.Errorf("hidden button is drawn: %q", row)
+	}
+
+	input(tcell.KeyRight)
+	if nav.btnActiveIndex != 3 {
+		t.Errorf("right: active %d, want 3", nav.btnActiveIndex)
+	}
+	input(tcell.KeyLeft)
+	if nav.btnActiveIndex != 0 {
+		t.Errorf("left: active %d, want 0", nav.btnActiveIndex)
+	}
+
+	input(tcell.KeyF2)
+	input(tcell.KeyF3)
+	x, y, _, _ := nav.buttons[1].GetRect()
+	nav.MouseHandler()(cview.MouseLeftClick, tcell.NewEventMouse(x, y, tcell.Button1, tcell.ModNone), func(p cview.Primitive) {})
+	if done != nil {
+		t.Errorf("disabled or hidden button was selected: %v", done)
+	}
+
+	nav.SetButtonDisabled(1, false)
+	nav.SetButtonHidden(2, false)
+	input(tcell.KeyF2)
+	input(tcell.KeyF3)
+	if !reflect.DeepEqual(done, []string{"b", "c"}) {
+		t.Errorf("enabled buttons: %v", done)
+	}
+
+	nav.btnActiveIndex = 3
+	nav.SetButtonDisabled(3, true)
+	if nav.btnActiveIndex != 2 {
+		t.Errorf("active button not moved from disabled button: %d", nav.btnActiveIndex)
+	}
+
+	nav.SetButtonDisabled(0, true)
+	nav.Blur()
+	nav.Focus(nil)
+	if nav.btnActiveIndex != 1 {
+		t.Errorf("focus on disabled first button: active %d, want 1", nav.btnActiveIndex)
+	}
+
+	colors := testNavBarColors()
+	colors.ButtonBackgroundDisabled = tcell.ColorDefault
+	colors.TextDisabled = tcell.ColorDefault
+	nav = NewNavBar(colors, nil)
+	nav.AddButton(cview.NewButton("a"), tcell.KeyF1)
+	nav.SetButtonDisabled(0, true)
+	if background, text := nav.disabledColors(); background != colors.ButtonBackground || text != cview.Styles.TertiaryTextColor {
+		t.Errorf("default disabled colors: got %v %v", background, text)
+	}
+	want := fmt.Sprintf("[#%06x]F1[-] a", cview.Styles.TertiaryTextColor.Hex())
+	if got := nav.buttons[0].GetLabel(); got != want {
+		t.Errorf("disabled label with default colors: got %s, want %s", got, want)
+	}
+
+	colors.ButtonBackgroundDisabled = tcell.ColorBlack
+	colors.TextDisabled = tcell.ColorBlack
+	if background, text := nav.disabledColors(); background != tcell.ColorBlack || text != tcell.ColorBlack {
+		t.Errorf("black disabled colors: got %v %v", background, text)
+	}
+}
+
+func TestNavBar_mouse(t *testing.T) {
+	var done []string
+	nav := NewNavBar(testNavBarColors(), func(label string) {
+		done = append(done, label)
+	})
+	for i, label := range []string{"a", "b", "c"} {
+		nav.AddButton(cview.NewButton(label), tcell.KeyF1+tcell.Key(i))
+	}
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("init screen: %v", err)
+	}
+	screen.SetSize(60, 1)
+	nav.SetRect(0, 0, 60, 1)
+	nav.Draw(screen)
+
+	var focused cview.Primitive
+	mouse := func(action cview.MouseAction, button int) {
+		x, y, _, _ := nav.buttons[button].GetRect()
+		event := tcell.NewEventMouse(x, y, tcell.ButtonNone, tcell.ModNone)
+		nav.MouseHandler()(action, event, func(p cview.Primitive) {
+			focused = p
+			p.Focus(nil)
+		})
+	}
+
+	mouse(cview.MouseLeftClick, 2)
+	if nav.btnActiveIndex != 2 || !reflect.DeepEqual(done, []string{"c"}) || focused != nav {
+		t.Errorf("click: active %d, done %v, focused %v", nav.btnActiveIndex, done, focused)
+	}
+	if !nav.buttons[2].HasFocus() || nav.buttons[0].HasFocus() {
+		t.Errorf("clicked button does not have focus")
+	}
+
+	mouse(cview.MouseLeftDoubleClick, 2)
+	if !reflect.DeepEqual(done, []string{"c", "c"}) {
+		t.Errorf("double click: done %v", done)
+	}
+
+	mouse(cview.MouseMove, 1)
+	if nav.hover != nav.buttons[1] {
+		t.Errorf("hovered button not highlighted")
+	}
+	nav.MouseHandler()(cview.MouseMove, tcell.NewEventMouse(0, 5, tcell.ButtonNone, tcell.ModNone), nil)
+	if nav.hover != nil {
+		t.Errorf("hover not cleared")
+	}
+
+	mouse(cview.MouseScrollDown, 1)
+	if nav.btnActiveIndex != 0 {
+		t.Errorf("wheel down: active %d, want 0", nav.btnActiveIndex)
+	}
+	mouse(cview.MouseScrollUp, 1)
+	if nav.btnActiveIndex != 2 || len(done) != 2 {
+		t.Errorf("wheel up: active %d, done %v", nav.btnActiveIndex, done)
+	}
+}
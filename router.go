@@ -0,0 +1,310 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// routerHistoryLimit is max number of views kept in back history
+const routerHistoryLimit = 100
+
+// routerView is a single view in router
+type routerView struct {
+	primitive cview.Primitive
+	// focus is primitive that had focus when view was left
+	focus cview.Primitive
+}
+
+// Router shows one named view at a time and keeps history of shown views. Views are in pages, that are
+// drawn inside a ModalLayout, so modals can be shown on top of any view. If router has NavBar, navbar is
+// in tab mode and its tabs switch views. Router restores focus to primitive that was focused when view was
+// left. Use HandleKey as application input capture to go back with Backspace or Alt+Left, and forward with
+// Alt+Right.
+type Router struct {
+	layout *ModalLayout
+	pages  *cview.Pages
+	navBar *NavBar
+
+	views       map[string]*routerView
+	current     string
+	back        []string
+	forward     []string
+	changedFunc func(from, to string)
+	getFocus    func() cview.Primitive
+	setFocus    func(p cview.Primitive)
+	// switching is set while router changes pages itself
+	switching bool
+	visible   bool
+}
+
+// NewRouter creates new router. NavBar can be nil.
+func NewRouter(navBar *NavBar) *Router {
+	r := &Router{
+		layout:  NewModalLayout(),
+		pages:   cview.NewPages(),
+		navBar:  navBar,
+		views:   map[string]*routerView{},
+		back:    []string{},
+		forward: []string{},
+		visible: true,
+	}
+	r.layout.Grid().AddItem(r.pages, 0, 0, 10, 10, 1, 1, false)
+	r.pages.SetChangedFunc(r.pagesChanged)
+	if navBar != nil {
+		navBar.SetPages(r.pages)
+	}
+	return r
+}
+
+// AddView adds view with name. First view is shown initially. If router has navbar and button is not nil,
+// button is added as a tab for view. If shortcut is already used by another button, error is returned and
+// view is not added.
+func (r *Router) AddView(name string, view cview.Primitive, button *cview.Button, shortcut Shortcut) error {
+	if r.navBar != nil && button != nil {
+		err := r.navBar.AddTab(button, name, shortcut)
+		if err != nil {
+			return err
+		}
+	}
+	r.views[name] = &routerView{primitive: view}
+	r.switching = true
+	r.pages.AddPage(name, view, true, r.current == "")
+	r.switching = false
+	if r.current == "" {
+		r.current = name
+	}
+	return nil
+}
+
+// SetChangedFunc sets function that is called whenever view changes. From is empty for first view.
+func (r *Router) SetChangedFunc(changed func(from, to string)) {
+	r.changedFunc = changed
+}
+
+// SetFocusFunc sets functions to get and set application focus, e.g. cview.Application.GetFocus and
+// cview.Application.SetFocus. These are required for restoring focus.
+func (r *Router) SetFocusFunc(getFocus func() cview.Primitive, setFocus func(p cview.Primitive)) {
+	r.getFocus = getFocus
+	r.setFocus = setFocus
+}
+
+// Navigate shows view with name and adds current view to back history. Forward history is cleared.
+func (r *Router) Navigate(name string) error {
+	if _, ok := r.views[name]; !ok {
+		return fmt.Errorf("no view %s", name)
+	}
+	if name == r.current {
+		return nil
+	}
+	r.pushBack(r.current)
+	r.forward = r.forward[:0]
+	r.switchTo(name, true)
+	return nil
+}
+
+// Back shows previous view. Return false if there is no history.
+func (r *Router) Back() bool {
+	if len(r.back) == 0 {
+		return false
+	}
+	name := r.back[len(r.back)-1]
+	r.back = r.back[:len(r.back)-1]
+	r.forward = append(r.forward, r.current)
+	r.switchTo(name, true)
+	return true
+}
+
+// Forward shows view that was left with Back. Return false if there is no such view.
+func (r *Router) Forward() bool {
+	if len(r.forward) == 0 {
+		return false
+	}
+	name := r.forward[len(r.forward)-1]
+	r.forward = r.forward[:len(r.forward)-1]
+	r.pushBack(r.current)
+	r.switchTo(name, true)
+	return true
+}
+
+// CanGoBack returns true if there is back history.
+func (r *Router) CanGoBack() bool {
+	return len(r.back) > 0
+}
+
+// CanGoForward returns true if there is forward history.
+func (r *Router) CanGoForward() bool {
+	return len(r.forward) > 0
+}
+
+// GetCurrentView returns name of current view.
+func (r *Router) GetCurrentView() string {
+	return r.current
+}
+
+// Layout returns modal layout that views are drawn in. Use it to show modals.
+func (r *Router) Layout() *ModalLayout {
+	return r.layout
+}
+
+// Pages returns pages that contain views.
+func (r *Router) Pages() *cview.Pages {
+	return r.pages
+}
+
+// HandleKey handles Backspace and Alt+Left for going back and Alt+Right for going forward.
+// Keys are ignored when a modal is shown, and Backspace when an input field or a primitive that is editing
+// (implements IsEditing() bool, e.g. Table) has focus. Set this as application input capture,
+// or call it from one. Returns nil if event was handled.
+func (r *Router) HandleKey(event *tcell.EventKey) *tcell.EventKey {
+	if r.layout.hasModal {
+		return event
+	}
+	alt := event.Modifiers()&tcell.ModAlt != 0
+	switch event.Key() {
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if alt || r.inputFocused() || !r.Back() {
+			return event
+		}
+	case tcell.KeyLeft:
+		if !alt || !r.Back() {
+			return event
+		}
+	case tcell.KeyRight:
+		if !alt || !r.Forward() {
+			return event
+		}
+	default:
+		return event
+	}
+	return nil
+}
+
+// inputFocused returns true if focused primitive takes text input
+func (r *Router) inputFocused() bool {
+	if r.getFocus == nil {
+		return false
+	}
+	switch p := r.getFocus().(type) {
+	case *cview.InputField:
+		return true
+	case interface{ IsEditing() bool }:
+		return p.IsEditing()
+	}
+	return false
+}
+
+// pushBack adds view to back history
+func (r *Router) pushBack(name string) {
+	r.back = append(r.back, name)
+	if len(r.back) > routerHistoryLimit {
+		r.back = r.back[len(r.back)-routerHistoryLimit:]
+	}
+}
+
+// switchTo shows view. If restoreFocus, focus is moved to previously focused primitive in view.
+func (r *Router) switchTo(name string, restoreFocus bool) {
+	from := r.current
+	if view := r.views[from]; view != nil && r.getFocus != nil {
+		focus := r.getFocus()
+		if focus != nil && focus != cview.Primitive(r.navBar) {
+			view.focus = focus
+		}
+	}
+
+	r.switching = true
+	r.pages.SwitchToPage(name)
+	r.switching = false
+	r.current = name
+
+	if restoreFocus && r.setFocus != nil {
+		view := r.views[name]
+		if view.focus != nil {
+			r.setFocus(view.focus)
+		} else {
+			r.setFocus(view.primitive)
+		}
+	}
+	if r.changedFunc != nil {
+		r.changedFunc(from, name)
+	}
+}
+
+// pagesChanged records view change made outside router, e.g. with navbar tabs
+func (r *Router) pagesChanged() {
+	if r.switching {
+		return
+	}
+	front, _ := r.pages.GetFrontPage()
+	if front == r.current || r.views[front] == nil {
+		return
+	}
+	r.pushBack(r.current)
+	r.forward = r.forward[:0]
+	r.switchTo(front, false)
+}
+
+func (r *Router) GetVisible() bool {
+	return r.visible
+}
+
+func (r *Router) SetVisible(v bool) {
+	r.visible = v
+}
+
+func (r *Router) Draw(screen tcell.Screen) {
+	r.layout.Draw(screen)
+}
+
+func (r *Router) GetRect() (int, int, int, int) {
+	return r.layout.GetRect()
+}
+
+func (r *Router) SetRect(x, y, width, height int) {
+	r.layout.SetRect(x, y, width, height)
+}
+
+func (r *Router) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+	return func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+		if r.HandleKey(event) == nil {
+			return
+		}
+		r.pages.InputHandler()(event, setFocus)
+	}
+}
+
+func (r *Router) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+	return r.layout.Grid().MouseHandler()
+}
+
+func (r *Router) Focus(delegate func(p cview.Primitive)) {
+	if view := r.views[r.current]; view != nil && view.focus != nil {
+		delegate(view.focus)
+		return
+	}
+	delegate(r.pages)
+}
+
+func (r *Router) Blur() {
+	r.layout.Blur()
+}
+
+func (r *Router) GetFocusable() cview.Focusable {
+	return r.layout.GetFocusable()
+}
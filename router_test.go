@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"reflect"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	nav := NewNavBar(testNavBarColors(), nil)
+	router := NewRouter(nav)
+	var changes []string
+	router.SetChangedFunc(func(from, to string) {
+		changes = append(changes, from+">"+to)
+	})
+	var focus cview.Primitive
+	router.SetFocusFunc(func() cview.Primitive {
+		return focus
+	}, func(p cview.Primitive) {
+		focus = p
+	})
+
+	views := map[string]*cview.Box{}
+	for i, name := range []string{"home", "search", "settings"} {
+		views[name] = cview.NewBox()
+		err := router.AddView(name, views[name], cview.NewButton(name), KeyShortcut(tcell.KeyF1+tcell.Key(i)))
+		if err != nil {
+			t.Fatalf("add view: %v", err)
+		}
+	}
+	if err := router.Navigate("unknown"); err == nil {
+		t.Errorf("navigated to unknown view")
+	}
+
+	input := views["search"]
+	key := func(key tcell.Key, mods tcell.ModMask) *tcell.EventKey {
+		return router.HandleKey(tcell.NewEventKey(key, 0, mods))
+	}
+	steps := []struct {
+		name    string
+		action  func()
+		current string
+		focus   cview.Primitive
+	}{
+		{"navigate", func() { router.Navigate("search") }, "search", views["search"]},
+		{"navigate again", func() { focus = input; router.Navigate("settings") }, "settings", views["settings"]},
+		{"back restores focus", func() { key(tcell.KeyLeft, tcell.ModAlt) }, "search", input},
+		{"backspace", func() { key(tcell.KeyBackspace2, tcell.ModNone) }, "home", views["home"]},
+		{"no more history", func() { key(tcell.KeyBackspace2, tcell.ModNone) }, "home", views["home"]},
+		{"forward", func() { key(tcell.KeyRight, tcell.ModAlt) }, "search", input},
+		{"navbar tab", func() {
+			focus = nav
+			nav.InputHandler()(tcell.NewEventKey(tcell.KeyF3, 0, tcell.ModNone), nil)
+		}, "settings", nav},
+		{"back from tab", func() { router.Back() }, "search", input},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.action()
+			if router.GetCurrentView() != step.current || focus != step.focus {
+				t.Errorf("current %s, want %s, focus changed: %v", router.GetCurrentView(), step.current,
+					focus != step.focus)
+			}
+			if front, _ := router.Pages().GetFrontPage(); front != step.current {
+				t.Errorf("front page %s", front)
+			}
+			nav.updateTabs()
+			if active := nav.GetButtonLabel(nav.GetActiveTab()); active != step.current {
+				t.Errorf("active tab %s", active)
+			}
+		})
+	}
+
+	want := []string{"home>search", "search>settings", "settings>search", "search>home", "home>search",
+		"search>settings", "settings>search"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes %v, want %v", changes, want)
+	}
+	if !router.CanGoBack() || !router.CanGoForward() {
+		t.Errorf("history: back %v, forward %v", router.back, router.forward)
+	}
+
+	focus = cview.NewInputField()
+	if key(tcell.KeyBackspace2, tcell.ModNone) == nil {
+		t.Errorf("backspace in input field was handled")
+	}
+	if key(tcell.KeyLeft, tcell.ModNone) == nil {
+		t.Errorf("left without alt was handled")
+	}
+}
+
+func TestRouter_tableSearch(t *testing.T) {
+	router := NewRouter(NewNavBar(testNavBarColors(), nil))
+	table := NewTable()
+	table.SetColumns([]string{"name"})
+	table.AddRow(0, "abc")
+	table.SetSearchEnabled(true)
+	router.SetFocusFunc(func() cview.Primitive {
+		return table
+	}, func(p cview.Primitive) {})
+	router.AddView("home", cview.NewBox(), cview.NewButton("home"), KeyShortcut(tcell.KeyF1))
+	router.AddView("table", table, cview.NewButton("table"), KeyShortcut(tcell.KeyF2))
+	router.Navigate("table")
+
+	// Application passes keys not captured by router to focused table
+	input := func(key tcell.Key, r rune) {
+		event := tcell.NewEventKey(key, r, tcell.ModNone)
+		if router.HandleKey(event) != nil {
+			table.InputHandler()(event, func(p cview.Primitive) {})
+		}
+	}
+	for _, r := range "/ab" {
+		input(tcell.KeyRune, r)
+	}
+	input(tcell.KeyBackspace2, 0)
+	if router.GetCurrentView() != "table" || table.GetSearchQuery() != "a" {
+		t.Errorf("backspace in search prompt: view %s, query %s", router.GetCurrentView(), table.GetSearchQuery())
+	}
+
+	input(tcell.KeyEscape, 0)
+	input(tcell.KeyBackspace2, 0)
+	if router.GetCurrentView() != "home" {
+		t.Errorf("backspace after closing search prompt: view %s", router.GetCurrentView())
+	}
+}
@@ -60,6 +60,8 @@ type ScrollList struct {
 	blurFunc         func(key tcell.Key)
 	indexChangedFunc func(int) bool
 
+	clipboard clipboard
+
 	// PreInputHandler is called before actual InputHandler, if any.
     PreInputHandler func(event *tcell.EventKey, setFocus func(p cview.Primitive))
 }
@@ -186,6 +188,8 @@ func (s *ScrollList) InputHandler() func(event *tcell.EventKey, setFocus func(p
 				pageUp = true
 			} else if r == 'G' {
 				pagedDown = true
+			} else if r == 'y' {
+				s.CopySelected()
 			}
 		}
 
@@ -214,6 +218,24 @@ func (s *ScrollList) InputHandler() func(event *tcell.EventKey, setFocus func(p
 	})
 }
 
+// SetCopyFunc sets function that is called whenever user copies item. Copied text is sent
+// to terminal with OSC 52 and osc52 tells whether screen was able to send it.
+// Use this as fallback for e.g. local clipboard tools.
+func (s *ScrollList) SetCopyFunc(copyFunc func(text string, osc52 bool)) {
+	s.clipboard.copyFunc = copyFunc
+}
+
+// CopySelected copies text of selected item to clipboard, if item implements CopyableItem.
+// User can copy item with 'y'.
+func (s *ScrollList) CopySelected() {
+	if s.selected < 0 || s.selected >= len(s.items) {
+		return
+	}
+	if item, ok := s.items[s.selected].(CopyableItem); ok {
+		s.clipboard.copy(item.CopyText())
+	}
+}
+
 // MouseHandler returns the mouse handler for this primitive.
 func (s *ScrollList) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
 	return s.WrapMouseHandler(func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
@@ -444,6 +466,7 @@ func (s *ScrollList) SetBorder(b bool) *cview.Box {
 
 func (s *ScrollList) Draw(screen tcell.Screen) {
 	s.Grid.Draw(screen)
+	s.clipboard.flush(screen)
 	if s.contextMenuItems() > 0 {
 		if s.ContextMenuList().HasFocus() {
 			list := s.ContextMenu.ContextMenuList()
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// SideBar is a vertical navigation bar. Each item has a label, an optional icon and an optional shortcut.
+// User can move between items with up and down arrows and select item with Enter, shortcut or mouse click.
+// Sidebar can be collapsed to show icons only. Like with NavBar, doneFunc is called with label
+// of the selected item.
+type SideBar struct {
+	*cview.Box
+	labels    []string
+	icons     []string
+	keys      []Shortcut
+	doneFunc  func(label string)
+	colors    *NavBarColors
+	active    int
+	offset    int
+	collapsed bool
+}
+
+// NewSideBar creates new sidebar. DoneFunc is called with items label whenever user selects some item.
+// DoneFunc can be set nil.
+func NewSideBar(colors *NavBarColors, doneFunc func(label string)) *SideBar {
+	s := &SideBar{
+		Box:      cview.NewBox(),
+		labels:   []string{},
+		icons:    []string{},
+		keys:     []Shortcut{},
+		doneFunc: doneFunc,
+		colors:   colors,
+	}
+	s.SetBackgroundColor(colors.Background)
+	return s
+}
+
+// AddItem adds a new item below existing items. Icon is shown before label, or alone if sidebar is
+// collapsed. Icon can be e.g. a single glyph or empty. If shortcut is already used by another item,
+// error is returned and item is not added. Zero shortcut adds item without shortcut.
+func (s *SideBar) AddItem(label, icon string, shortcut Shortcut) error {
+	if !shortcut.IsZero() {
+		for i, v := range s.keys {
+			if v.normalize() == shortcut.normalize() {
+				return fmt.Errorf("shortcut %s already used by item %s", shortcut, s.labels[i])
+			}
+		}
+	}
+	s.labels = append(s.labels, label)
+	s.icons = append(s.icons, icon)
+	s.keys = append(s.keys, shortcut)
+	return nil
+}
+
+// GetItemCount returns number of items.
+func (s *SideBar) GetItemCount() int {
+	return len(s.labels)
+}
+
+// SetActive sets item at index active.
+func (s *SideBar) SetActive(index int) {
+	if index >= 0 && index < len(s.labels) {
+		s.active = index
+	}
+}
+
+// GetActive returns index of active item.
+func (s *SideBar) GetActive() int {
+	return s.active
+}
+
+// SetCollapsed sets whether only icons are shown. Items without icon show first letter of label.
+// Use GetPreferredWidth to resize sidebar in layout.
+func (s *SideBar) SetCollapsed(collapsed bool) {
+	s.collapsed = collapsed
+}
+
+// IsCollapsed returns true if only icons are shown.
+func (s *SideBar) IsCollapsed() bool {
+	return s.collapsed
+}
+
+// GetPreferredWidth returns width that fits all items, including borders and padding.
+func (s *SideBar) GetPreferredWidth() int {
+	width := 0
+	for i := range s.labels {
+		text, shortcut := s.itemText(i)
+		itemWidth := cview.TaggedStringWidth(text) + 2
+		if shortcut != "" {
+			itemWidth += cview.TaggedStringWidth(shortcut) + 1
+		}
+		width = max(width, itemWidth)
+	}
+	_, _, innerWidth, _ := s.GetInnerRect()
+	_, _, boxWidth, _ := s.GetRect()
+	return width + boxWidth - innerWidth
+}
+
+// InputHandler handles up and down arrows, Enter and shortcuts.
+func (s *SideBar) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+	return s.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+		if len(s.labels) == 0 {
+			return
+		}
+		switch event.Key() {
+		case tcell.KeyDown:
+			s.active = min(len(s.labels)-1, s.active+1)
+			return
+		case tcell.KeyUp:
+			s.active = max(0, s.active-1)
+			return
+		case tcell.KeyEnter:
+			s.selectItem(s.active)
+			return
+		}
+		for i, v := range s.keys {
+			if v.Matches(event) {
+				s.selectItem(i)
+				return
+			}
+		}
+	})
+}
+
+// MouseHandler selects clicked item.
+func (s *SideBar) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+	return s.WrapMouseHandler(func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+		if !s.InRect(event.Position()) {
+			return false, nil
+		}
+		if action != cview.MouseLeftClick {
+			return false, nil
+		}
+		setFocus(s)
+		_, y, _, height := s.GetInnerRect()
+		_, mouseY := event.Position()
+		index := s.offset + mouseY - y
+		if mouseY >= y && mouseY < y+height && index < len(s.labels) {
+			s.selectItem(index)
+		}
+		return true, nil
+	})
+}
+
+// Draw draws items, scrolling them so that active item is visible.
+func (s *SideBar) Draw(screen tcell.Screen) {
+	if s.HasFocus() {
+		s.SetBackgroundColor(s.colors.BackgroundFocus)
+	} else {
+		s.SetBackgroundColor(s.colors.Background)
+	}
+	s.Box.Draw(screen)
+	x, y, width, height := s.GetInnerRect()
+	if height <= 0 || width <= 0 {
+		return
+	}
+	if s.active < s.offset {
+		s.offset = s.active
+	} else if s.active >= s.offset+height {
+		s.offset = s.active - height + 1
+	}
+	s.offset = max(0, min(s.offset, len(s.labels)-height))
+
+	for row := 0; row < height && s.offset+row < len(s.labels); row++ {
+		index := s.offset + row
+		background := s.colors.ButtonBackground
+		text := s.colors.Text
+		shortcutColor := s.colors.Shortcut
+		if index == s.active && s.HasFocus() {
+			background = s.colors.ButtonBackgroundFocus
+			text = s.colors.TextFocus
+			shortcutColor = s.colors.ShortcutFocus
+		}
+		style := tcell.StyleDefault.Background(background)
+		for i := 0; i < width; i++ {
+			screen.SetContent(x+i, y+row, ' ', nil, style)
+		}
+
+		label, shortcut := s.itemText(index)
+		if s.collapsed {
+			cview.Print(screen, label, x, y+row, width, cview.AlignCenter, text)
+			continue
+		}
+		shortcutWidth := 0
+		if shortcut != "" {
+			shortcutWidth, _ = cview.Print(screen, shortcut, x+1, y+row, width-2, cview.AlignRight, shortcutColor)
+		}
+		cview.Print(screen, label, x+1, y+row, max(0, width-2-shortcutWidth-1), cview.AlignLeft, text)
+	}
+}
+
+// itemText returns text and shortcut text for item
+func (s *SideBar) itemText(index int) (string, string) {
+	icon := s.icons[index]
+	label := cview.Escape(s.labels[index])
+	if s.collapsed {
+		if icon == "" && len(s.labels[index]) > 0 {
+			return cview.Escape(string([]rune(s.labels[index])[0])), ""
+		}
+		return icon, ""
+	}
+	if icon != "" {
+		label = icon + " " + label
+	}
+	return label, cview.Escape(s.keys[index].String())
+}
+
+// selectItem sets item active and calls doneFunc
+func (s *SideBar) selectItem(index int) {
+	s.active = index
+	if s.doneFunc != nil {
+		s.doneFunc(s.labels[index])
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"reflect"
+	"testing"
+)
+
+// screenRow returns text of screen row
+func screenRow(screen tcell.SimulationScreen, y int) string {
+	cells, width, _ := screen.GetContents()
+	text := ""
+	for _, cell := range cells[y*width : (y+1)*width] {
+		text += string(cell.Runes)
+	}
+	return text
+}
+
+func TestSideBar(t *testing.T) {
+	var done []string
+	side := NewSideBar(testNavBarColors(), func(label string) {
+		done = append(done, label)
+	})
+	items := []struct {
+		label    string
+		icon     string
+		shortcut Shortcut
+	}{
+		{"Home", "⌂", KeyShortcut(tcell.KeyF1)},
+		{"Search", "", RuneShortcut('s', tcell.ModNone)},
+		{"Settings", "⚙", Shortcut{}},
+	}
+	for _, item := range items {
+		if err := side.AddItem(item.label, item.icon, item.shortcut); err != nil {
+			t.Fatalf("add item: %v", err)
+		}
+	}
+	if err := side.AddItem("Save", "", RuneShortcut('s', tcell.ModNone)); err == nil {
+		t.Errorf("conflicting shortcut was accepted")
+	}
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("init screen: %v", err)
+	}
+	screen.SetSize(16, 3)
+	side.SetRect(0, 0, 16, 3)
+	side.Focus(nil)
+	side.Draw(screen)
+	screen.Show()
+	rows := []string{screenRow(screen, 0), screenRow(screen, 1), screenRow(screen, 2)}
+	want := []string{" ⌂ Home      F1 ", " Search       s ", " ⚙ Settings     "}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expanded rows: %q, want %q", rows, want)
+	}
+	if width := side.GetPreferredWidth(); width != 12 {
+		t.Errorf("preferred width %d, want 12", width)
+	}
+
+	side.SetCollapsed(true)
+	if width := side.GetPreferredWidth(); width != 3 {
+		t.Errorf("collapsed width %d, want 3", width)
+	}
+	screen.SetSize(3, 3)
+	side.SetRect(0, 0, 3, 3)
+	side.Draw(screen)
+	screen.Show()
+	rows = []string{screenRow(screen, 0), screenRow(screen, 1), screenRow(screen, 2)}
+	want = []string{" ⌂ ", " S ", " ⚙ "}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("collapsed rows: %q, want %q", rows, want)
+	}
+
+	input := func(key tcell.Key, r rune) {
+		side.InputHandler()(tcell.NewEventKey(key, r, tcell.ModNone), func(p cview.Primitive) {})
+	}
+	input(tcell.KeyDown, 0)
+	input(tcell.KeyDown, 0)
+	input(tcell.KeyDown, 0)
+	input(tcell.KeyEnter, 0)
+	input(tcell.KeyRune, 's')
+	input(tcell.KeyUp, 0)
+	if !reflect.DeepEqual(done, []string{"Settings", "Search"}) || side.GetActive() != 0 {
+		t.Errorf("done %v, active %d", done, side.GetActive())
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell"
 	"gitlab.com/tslocum/cview"
+	"sync"
+	"time"
 )
 
 const (
@@ -42,6 +44,9 @@ const (
 type Table struct {
 	*cview.Table
 
+	// padding is border padding set by user: top, bottom, left, right
+	padding [4]int
+
 	columns          []string
 	columnWidths     []int
 	columnExpansions []int
@@ -51,6 +56,67 @@ type Table struct {
 
 	sortFunc    func(col string, sort Sort)
 	addCellFunc func(cell *cview.TableCell, header bool, col int)
+
+	editable bool
+	editors  map[int]*ColumnEditor
+	editor   *cellEditor
+	editFunc func(index, column int, value string) error
+
+	multiSelect           bool
+	selectedRows          map[*cview.TableCell]bool
+	selectAnchor          int
+	multiSelectText       tcell.Color
+	multiSelectBackground tcell.Color
+	cellStyles            map[*cview.TableCell]cellStyle
+
+	columnTypes  []Column
+	numberFormat NumberFormat
+
+	aggregations map[int]Aggregation
+	footerLabel  string
+	footer       []*cview.TableCell
+	footerDirty  bool
+
+	groupBy           int
+	groupsDirty       bool
+	groupSubtotals    bool
+	groupIndexRestart bool
+	collapsedGroups   map[string]bool
+
+	frozenColumns int
+
+	styleRules      []StyleRule
+	columnStats     map[int]*columnStats
+	stylesDirty     bool
+	styledSelection int
+
+	rowKeys       map[*cview.TableCell]string
+	flashes       map[*cview.TableCell]cellFlash
+	flashUp       tcell.Color
+	flashDown     tcell.Color
+	flashChanged  tcell.Color
+	flashDuration time.Duration
+	redrawFunc    func()
+	animationLock sync.Mutex
+	animating     bool
+	animateUntil  time.Time
+
+	follow    bool
+	following bool
+	followRow int
+	newRows   int
+	rowsAdded int
+	rowLimit  int
+	// indexOffset is added to numbers in index column, e.g. count of dropped rows or rows in previous pages
+	indexOffset int
+
+	search *tableSearch
+
+	tree *tableTree
+
+	clipboard clipboard
+
+	pager *tablePager
 }
 
 // NewTable creates new table instance
@@ -59,13 +125,19 @@ func NewTable() *Table {
 		Table: cview.NewTable(),
 	}
 
-	t.Table.SetFixed(1, 100)
-	t.Table.SetSelectable(true, false)
+	t.Table.SetFixed(1, 0)
+	t.setRowsSelectable()
 	t.sortCol = 0
 	t.sortType = SortAsc
+	t.numberFormat = NumberFormatPlain
+	t.groupBy = -1
+	t.multiSelectText = cview.Styles.PrimaryTextColor
+	t.multiSelectBackground = cview.Styles.MoreContrastBackgroundColor
+	t.flashUp = tcell.ColorDarkGreen
+	t.flashDown = tcell.ColorDarkRed
+	t.flashChanged = tcell.ColorDarkBlue
+	t.flashDuration = time.Second * 2
 	t.SetCellSimple(0, 0, "#")
-
-	t.SetFixed(1, 10)
 	return t
 }
 
@@ -77,7 +149,9 @@ func (t *Table) SetSortFunc(sortFunc func(column string, sort Sort)) *Table {
 
 // SetAddCellFunc add function callback that gets called every time a new cell is added with flag of whether
 // the cell is in header row. Use this to modify e.g. style of the cell when it gets added to table.
-func (t *Table) SetAddCellFunc(cellFunc func(cell *cview.TableCell, header bool, col int)) *Table {
+// Last argument is row of the cell: 0 for header and index+1 for data rows. Group header rows are also flagged
+// as header with their row, and footer cells are flagged as header with row after last row.
+func (t *Table) SetAddCellFunc(cellFunc func(cell *cview.TableCell, header bool, row int)) *Table {
 	t.addCellFunc = cellFunc
 	return t
 }
@@ -117,39 +191,205 @@ func (t *Table) Clear(headers bool) *Table {
 			t.Table.SetCell(0, i, cells[i])
 		}
 	}
+	t.selectedRows = map[*cview.TableCell]bool{}
+	t.selectAnchor = 0
+	t.cellStyles = map[*cview.TableCell]cellStyle{}
+	t.rowKeys = map[*cview.TableCell]string{}
+	t.flashes = map[*cview.TableCell]cellFlash{}
+	t.indexOffset = 0
+	t.newRows = 0
+	t.followRow = -1
+	t.dataChanged()
 	t.SetOffset(1, 0)
 	return t
 }
 
 //AddRow adds single row to table
 func (t *Table) AddRow(index int, content ...string) *Table {
-	count := len(content)
+	t.addRow(index, content, nil)
+	return t
+}
+
+// addRow adds row with texts. If values is not nil, each cell gets value as its reference.
+func (t *Table) addRow(index int, texts []string, values []interface{}) {
+	row := index + 1
+	if t.grouped() {
+		row = t.Table.GetRowCount()
+		t.groupsDirty = true
+	} else if t.rowLimit > 0 {
+		// Dropped rows shift indices, keep appending to the end
+		row = t.Table.GetRowCount()
+		index = row - 1
+	}
+	for i, cell := range t.newRowCells(index, texts, values) {
+		t.Table.SetCell(row, i, cell)
+	}
+	t.rowsAdded++
+	t.dataChanged()
+	t.limitRows()
+}
+
+// newRowCells creates cells for data row, including index cell if shown.
+func (t *Table) newRowCells(index int, texts []string, values []interface{}) []*cview.TableCell {
+	count := len(texts)
 
 	cells := make([]*cview.TableCell, count, count+1)
-	for i := 0; i < len(content); i++ {
-		cells[i] = cview.NewTableCell(content[i])
+	for i := 0; i < count; i++ {
+		cells[i] = cview.NewTableCell(texts[i])
+		if values != nil {
+			cells[i].SetReference(values[i])
+		}
 	}
 
 	if t.showIndex {
-		cells = append([]*cview.TableCell{cview.NewTableCell(fmt.Sprint(index + 1))}, cells...)
+		number := t.indexOffset + index + 1
+		indexCell := cview.NewTableCell(fmt.Sprint(number))
+		indexCell.SetReference(number)
+		cells = append([]*cview.TableCell{indexCell}, cells...)
 	}
 
 	for i := 0; i < len(cells); i++ {
-		if len(t.columnWidths) >= i && t.columnWidths != nil {
+		if len(t.columnWidths) > i {
 			cells[i].SetMaxWidth(t.columnWidths[i])
 		}
-		if len(t.columnExpansions) >= i && t.columnExpansions != nil {
+		if len(t.columnExpansions) > i {
 			cells[i].SetExpansion(t.columnExpansions[i])
 		}
+		cells[i].SetAlign(t.alignment(i))
 
 		if t.addCellFunc != nil {
 			t.addCellFunc(cells[i], false, index+1)
 		}
-		t.Table.SetCell(index+1, i, cells[i])
 	}
+	return cells
+}
+
+// RemoveRow removes single row from table. Index is same as with AddRow. Rows after index are moved up.
+func (t *Table) RemoveRow(index int) *Table {
+	if t.grouped() {
+		rows := t.allDataRows()
+		if index < 0 || index >= len(rows) {
+			return t
+		}
+		delete(t.selectedRows, rows[index][0])
+		delete(t.rowKeys, rows[index][0])
+		t.setDataRows(append(rows[:index], rows[index+1:]...))
+		t.regroup()
+		return t
+	}
+	row := index + 1
+	if row < 1 || row >= t.Table.GetRowCount() {
+		return t
+	}
+	delete(t.selectedRows, t.Table.GetCell(row, 0))
+	delete(t.rowKeys, t.Table.GetCell(row, 0))
+	t.Table.RemoveRow(row)
+	t.dataChanged()
 	return t
 }
 
+// rowIndex returns data index of table row, same as with AddRow, or -1 if row is not a data row.
+// Group header rows are skipped and rows in collapsed groups are counted.
+func (t *Table) rowIndex(row int) int {
+	if row < 1 || row >= t.Table.GetRowCount() || t.groupAt(row) != nil {
+		return -1
+	}
+	if !t.grouped() {
+		return row - 1
+	}
+	index := 0
+	for i := 1; i < row; i++ {
+		if group := t.groupAt(i); group != nil {
+			index += len(group.rows)
+		} else {
+			index++
+		}
+	}
+	return index
+}
+
+// indexRow returns table row of data index, or -1 if there's no such row or it is in a collapsed group.
+func (t *Table) indexRow(index int) int {
+	rowCount := t.Table.GetRowCount()
+	if !t.grouped() {
+		if index < 0 || index+1 >= rowCount {
+			return -1
+		}
+		return index + 1
+	}
+	for row := 1; row < rowCount && index >= 0; row++ {
+		if group := t.groupAt(row); group != nil {
+			if index < len(group.rows) {
+				return -1
+			}
+			index -= len(group.rows)
+			continue
+		}
+		if index == 0 {
+			return row
+		}
+		index--
+	}
+	return -1
+}
+
+// indexCells returns cells of data row at index, including rows in collapsed groups, or nil.
+func (t *Table) indexCells(index int) []*cview.TableCell {
+	if t.grouped() {
+		rows := t.allDataRows()
+		if index < 0 || index >= len(rows) {
+			return nil
+		}
+		return rows[index]
+	}
+	row := t.indexRow(index)
+	if row < 0 {
+		return nil
+	}
+	cells := make([]*cview.TableCell, t.Table.GetColumnCount())
+	for col := range cells {
+		cells[col] = t.Table.GetCell(row, col)
+	}
+	return cells
+}
+
+// allDataRows returns all data rows in display order, including rows in collapsed groups.
+func (t *Table) allDataRows() [][]*cview.TableCell {
+	rowCount := t.Table.GetRowCount()
+	colCount := t.Table.GetColumnCount()
+	rows := make([][]*cview.TableCell, 0, rowCount)
+	for row := 1; row < rowCount; row++ {
+		if group := t.groupAt(row); group != nil {
+			rows = append(rows, group.rows...)
+			continue
+		}
+		cells := make([]*cview.TableCell, colCount)
+		for col := 0; col < colCount; col++ {
+			cells[col] = t.Table.GetCell(row, col)
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// setDataRows replaces all rows except header with given rows
+func (t *Table) setDataRows(rows [][]*cview.TableCell) {
+	header := make([]*cview.TableCell, t.Table.GetColumnCount())
+	for col := range header {
+		header[col] = t.Table.GetCell(0, col)
+	}
+	t.searchDataChanged()
+	t.Table.Clear()
+	for col, cell := range header {
+		t.Table.SetCell(0, col, cell)
+	}
+	for i, cells := range rows {
+		for col, cell := range cells {
+			t.Table.SetCell(i+1, col, cell)
+		}
+	}
+}
+
 // SetSort sets default sort column and type
 func (t *Table) SetSort(column int, sort Sort) *Table {
 	if t.showIndex && column == 0 {
@@ -165,6 +405,7 @@ func (t *Table) SetSort(column int, sort Sort) *Table {
 // SetColumns set column header names. This will clear the table
 func (t *Table) SetColumns(columns []string) *Table {
 	t.Clear(true)
+	t.columnTypes = nil
 	if t.showIndex {
 		columns = append([]string{"#"}, columns...)
 		if len(columns) >= 2 {
@@ -179,6 +420,7 @@ func (t *Table) SetColumns(columns []string) *Table {
 	}
 	for i := 0; i < len(columns); i++ {
 		cell := cview.NewTableCell(columns[i])
+		cell.SetAlign(t.alignment(i))
 		if t.addCellFunc != nil {
 			t.addCellFunc(cell, true, 0)
 		}
@@ -191,18 +433,59 @@ func (t *Table) SetColumns(columns []string) *Table {
 //Inputhandler handles header row inputs
 func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
 	return func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+		if t.editor != nil {
+			t.editorInput(event, setFocus)
+			return
+		}
+
+		if t.pager != nil && t.pager.sizeList != nil {
+			t.pageInput(event)
+			return
+		}
+
+		if t.searchKey(event) {
+			return
+		}
+
+		if t.pager != nil && t.pageInput(event) {
+			return
+		}
+
+		if t.copyInput(event) {
+			return
+		}
+
+		if t.multiSelect && t.multiSelectInput(event) {
+			return
+		}
+
 		enableHeader := false
 		key := event.Key()
-		if t.sortFunc != nil {
+		if key == tcell.KeyEnter {
+			row, _ := t.Table.GetSelection()
+			if t.toggleGroup(row) {
+				return
+			}
+		}
+		if t.tree != nil && t.treeInput(event) {
+			return
+		}
+		if t.editable && (key == tcell.KeyEnter || key == tcell.KeyRune && event.Rune() == 'e') {
+			row, col := t.Table.GetSelection()
+			if t.openEditor(row, col) {
+				return
+			}
+		}
+		if t.sortable() {
 			row, _ := t.Table.GetSelection()
 			if row == 1 && key == tcell.KeyUp {
 				enableHeader = true
 				t.Table.SetSelectable(true, true)
 				t.Table.Select(0, t.sortCol)
 			} else if row == 0 && key == tcell.KeyDown {
-				t.Table.SetSelectable(true, false)
+				t.setRowsSelectable()
 			}
-			if key == tcell.KeyEnter && row == 0 && t.sortFunc != nil {
+			if key == tcell.KeyEnter && row == 0 {
 				t.updateSort()
 			}
 		}
@@ -214,7 +497,7 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p cview
 		row, _ = t.Table.GetSelection()
 		if row == 0 && !atHeader && !enableHeader {
 			t.Table.Select(1, 0)
-			t.Table.SetSelectable(true, false)
+			t.setRowsSelectable()
 		} else if enableHeader {
 			t.Table.Select(0, t.sortCol)
 			t.Table.SetSelectable(true, true)
@@ -222,6 +505,123 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p cview
 	}
 }
 
+// MouseHandler handles mouse events. Clicking header sorts by column, clicking row selects it and
+// double-clicking row activates it as with Enter. Mouse wheel moves selection.
+func (t *Table) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+	return func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+		if !t.InRect(event.Position()) {
+			return false, nil
+		}
+		// Focus Table instead of underlying cview.Table
+		focus := func(p cview.Primitive) {
+			if p == cview.Primitive(t.Table) {
+				p = t
+			}
+			setFocus(p)
+		}
+
+		switch action {
+		case cview.MouseLeftClick, cview.MouseLeftDoubleClick:
+			lastRow, lastCol := t.Table.GetSelection()
+			// Let cview find clicked cell
+			t.Table.MouseHandler()(cview.MouseLeftClick, event, focus)
+			row, col := t.Table.GetSelection()
+			if t.editor != nil && (row != t.editor.row || col != t.editor.column) {
+				t.cancelEdit()
+			}
+
+			if row == 0 {
+				if t.sortable() && col >= 0 {
+					t.updateSort()
+				}
+				// Keep previous selection
+				t.Table.Select(lastRow, lastCol)
+				return true, nil
+			}
+			if row < 0 {
+				t.Table.Select(lastRow, lastCol)
+				return true, nil
+			}
+			if lastRow == 0 {
+				t.setRowsSelectable()
+			}
+			x, _ := event.Position()
+			if action == cview.MouseLeftDoubleClick {
+				// First click already toggled group or tree node
+				if t.groupAt(row) == nil && t.treeMarkerAt(row, x) == nil {
+					t.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), focus)
+				}
+				return true, nil
+			}
+			if t.toggleGroup(row) || t.treeClick(row, x) {
+				return true, nil
+			}
+			if t.multiSelect {
+				t.multiSelectClick(row, event.Modifiers())
+			}
+			return true, nil
+		case cview.MouseScrollUp, cview.MouseScrollDown:
+			if t.Table.GetRowCount() < 2 {
+				return true, nil
+			}
+			row, col := t.Table.GetSelection()
+			if row == 0 {
+				t.setRowsSelectable()
+			}
+			if action == cview.MouseScrollUp {
+				row = max(1, row-1)
+			} else {
+				row = min(t.Table.GetRowCount()-1, row+1)
+			}
+			t.Table.Select(row, col)
+			return true, nil
+		}
+		return t.Table.MouseHandler()(action, event, focus)
+	}
+}
+
+// SetBorderPadding sets padding inside table border. Footer is drawn above bottom padding.
+func (t *Table) SetBorderPadding(top, bottom, left, right int) *Table {
+	t.padding = [4]int{top, bottom, left, right}
+	t.Table.SetBorderPadding(top, bottom, left, right)
+	return t
+}
+
+// Draw draws table, footer and inline editor, if one is open.
+func (t *Table) Draw(screen tcell.Screen) {
+	t.applyPage()
+	if t.groupsDirty {
+		t.regroup()
+	}
+	t.updateFollow()
+	t.updateStyles()
+	restoreTexts := t.highlightMatches()
+	top, bottom, left, right := t.padding[0], t.padding[1], t.padding[2], t.padding[3]
+	footerRows := 0
+	if t.hasFooter() {
+		footerRows++
+	}
+	if t.pager != nil {
+		footerRows++
+	}
+	// Leave rows for footer and page indicator. Padding is kept so that inner rect excludes them.
+	t.Table.SetBorderPadding(top, bottom+footerRows, left, right)
+	t.Table.Draw(screen)
+	_, y, _, height := t.GetInnerRect()
+	y += height
+	if t.hasFooter() {
+		t.drawFooter(screen, y)
+		y++
+	}
+	t.drawPager(screen, y)
+	restoreTexts()
+	t.drawColumnIndicators(screen)
+	t.drawNewRowsIndicator(screen)
+	t.drawEditor(screen)
+	t.drawSearch(screen)
+	t.clipboard.flush(screen)
+}
+
 //update sort and call sortFunc if there is one
 func (t *Table) updateSort() {
 	_, col := t.GetSelection()
@@ -249,5 +649,27 @@ func (t *Table) updateSort() {
 	if t.sortFunc != nil {
 		name := t.columns[t.sortCol]
 		t.sortFunc(name, t.sortType)
+	} else if t.tree != nil {
+		t.sortTree()
+	} else if t.columnTypes != nil {
+		t.sortRows()
+	}
+	if t.grouped() {
+		t.regroup()
 	}
 }
+
+// dataChanged marks footer and styles to be recomputed after data has changed.
+func (t *Table) dataChanged() {
+	t.footerDirty = true
+	t.columnStats = nil
+	t.searchDataChanged()
+	if len(t.styleRules) > 0 {
+		t.stylesDirty = true
+	}
+}
+
+// sortable returns true if user can sort columns, either with sortFunc, typed columns or tree
+func (t *Table) sortable() bool {
+	return t.sortFunc != nil || t.columnTypes != nil || t.tree != nil
+}
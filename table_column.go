@@ -0,0 +1,475 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"gitlab.com/tslocum/cview"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnType is the type of values in a column. It defines how values are formatted, aligned and compared.
+type ColumnType int
+
+const (
+	// ColumnString is plain text
+	ColumnString ColumnType = iota
+	// ColumnInt is any integer type
+	ColumnInt
+	// ColumnFloat is float32 or float64
+	ColumnFloat
+	// ColumnDuration is time.Duration
+	ColumnDuration
+	// ColumnBytes is size in bytes as any integer type, printed with binary prefixes
+	ColumnBytes
+	// ColumnTime is time.Time
+	ColumnTime
+	// ColumnBool is bool
+	ColumnBool
+)
+
+// Column describes a typed column.
+type Column struct {
+	Name string
+	Type ColumnType
+	// Precision is number of decimals for ColumnFloat. If negative, shortest representation is used.
+	Precision int
+	// TimeLayout is layout for ColumnTime. If empty, '2006-01-02 15:04:05' is used.
+	TimeLayout string
+	// Format overrides default formatting for the type.
+	Format func(value interface{}) string
+}
+
+// NumberFormat describes how numbers are printed.
+type NumberFormat struct {
+	// GroupSeparator is put between each group of thousands. Empty disables grouping.
+	GroupSeparator string
+	// DecimalSeparator separates decimals
+	DecimalSeparator string
+}
+
+var (
+	// NumberFormatPlain prints numbers without grouping
+	NumberFormatPlain = NumberFormat{GroupSeparator: "", DecimalSeparator: "."}
+	// NumberFormatEnglish prints numbers as 1,234.5
+	NumberFormatEnglish = NumberFormat{GroupSeparator: ",", DecimalSeparator: "."}
+	// NumberFormatGerman prints numbers as 1.234,5
+	NumberFormatGerman = NumberFormat{GroupSeparator: ".", DecimalSeparator: ","}
+	// NumberFormatSI prints numbers as 1 234,5
+	NumberFormatSI = NumberFormat{GroupSeparator: " ", DecimalSeparator: ","}
+	// NumberFormatSwiss prints numbers as 1'234.5
+	NumberFormatSwiss = NumberFormat{GroupSeparator: "'", DecimalSeparator: "."}
+)
+
+// NumberFormatForLocale returns number format for locale, e.g. 'en_US.UTF-8', 'de-DE' or 'fi'.
+// Unknown locales get NumberFormatPlain.
+func NumberFormatForLocale(locale string) NumberFormat {
+	locale = strings.ToLower(strings.Split(locale, ".")[0])
+	locale = strings.Replace(locale, "-", "_", -1)
+	if strings.HasSuffix(locale, "_ch") {
+		return NumberFormatSwiss
+	}
+	lang := strings.Split(locale, "_")[0]
+	switch lang {
+	case "en", "ja", "ko", "zh", "he", "th":
+		return NumberFormatEnglish
+	case "de", "nl", "it", "es", "pt", "da", "id", "tr", "el":
+		return NumberFormatGerman
+	case "fi", "fr", "sv", "nb", "no", "ru", "pl", "cs", "sk", "uk", "hu", "et", "lt", "lv":
+		return NumberFormatSI
+	default:
+		return NumberFormatPlain
+	}
+}
+
+// SetTypedColumns sets columns with types. This will clear the table. Use AddValues to add rows
+// with raw values, that are then formatted and aligned per column type. Raw value is stored as cell reference.
+// If no sortFunc is set, table sorts rows by itself comparing raw values.
+func (t *Table) SetTypedColumns(columns []Column) *Table {
+	names := make([]string, len(columns))
+	for i, v := range columns {
+		names[i] = v.Name
+	}
+	t.SetColumns(names)
+
+	t.columnTypes = make([]Column, 0, len(columns)+1)
+	if t.showIndex {
+		t.columnTypes = append(t.columnTypes, Column{Name: "#", Type: ColumnInt})
+	}
+	t.columnTypes = append(t.columnTypes, columns...)
+	for i := range t.columnTypes {
+		t.Table.GetCell(0, i).SetAlign(t.alignment(i))
+	}
+	return t
+}
+
+// SetNumberFormat sets format for numbers in typed columns. Changing this does not update existing data.
+func (t *Table) SetNumberFormat(format NumberFormat) *Table {
+	t.numberFormat = format
+	return t
+}
+
+// AddValues adds single row of raw values to table. Values are formatted according to column types
+// set with SetTypedColumns.
+func (t *Table) AddValues(index int, values ...interface{}) *Table {
+	offset := 0
+	if t.showIndex {
+		offset = 1
+	}
+	texts := make([]string, len(values))
+	for i, v := range values {
+		texts[i] = t.formatValue(i+offset, v)
+	}
+	t.addRow(index, texts, values)
+	return t
+}
+
+// GetValue returns raw value of cell. Index is same as with AddRow and column includes index column if shown.
+// If cell has no raw value, its text is returned. If there's no such cell, nil is returned.
+func (t *Table) GetValue(index, column int) interface{} {
+	cells := t.indexCells(index)
+	if column < 0 || column >= len(cells) {
+		return nil
+	}
+	return cellValue(cells[column])
+}
+
+// column returns typed column, or nil if column is not typed
+func (t *Table) column(col int) *Column {
+	if col < 0 || col >= len(t.columnTypes) {
+		return nil
+	}
+	return &t.columnTypes[col]
+}
+
+// formatValue formats value for column
+func (t *Table) formatValue(col int, value interface{}) string {
+	column := t.column(col)
+	if column == nil {
+		return fmt.Sprint(value)
+	}
+	if column.Format != nil {
+		return column.Format(value)
+	}
+	return formatValue(column, t.numberFormat, value)
+}
+
+// parseValue parses text edited by user to column type. If column has no type or parsing fails,
+// text is returned.
+func (t *Table) parseValue(col int, text string) interface{} {
+	column := t.column(col)
+	if column == nil {
+		return text
+	}
+	var value interface{}
+	var err error
+	text = strings.TrimSpace(text)
+	switch column.Type {
+	case ColumnInt, ColumnBytes:
+		value, err = strconv.ParseInt(text, 10, 64)
+	case ColumnFloat:
+		value, err = strconv.ParseFloat(text, 64)
+	case ColumnDuration:
+		value, err = time.ParseDuration(text)
+	case ColumnTime:
+		layout := column.TimeLayout
+		if layout == "" {
+			layout = "2006-01-02 15:04:05"
+		}
+		value, err = time.Parse(layout, text)
+	case ColumnBool:
+		value, err = strconv.ParseBool(text)
+	default:
+		return text
+	}
+	if err != nil {
+		return text
+	}
+	return value
+}
+
+// alignment returns cell alignment for column
+func (t *Table) alignment(col int) int {
+	column := t.column(col)
+	if column == nil {
+		return cview.AlignLeft
+	}
+	switch column.Type {
+	case ColumnInt, ColumnFloat, ColumnDuration, ColumnBytes:
+		return cview.AlignRight
+	default:
+		return cview.AlignLeft
+	}
+}
+
+// sortRows sorts data rows by sort column, comparing raw values.
+func (t *Table) sortRows() {
+	rows := t.allDataRows()
+	if len(rows) < 2 {
+		return
+	}
+
+	columnType := ColumnString
+	if column := t.column(t.sortCol); column != nil {
+		columnType = column.Type
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i][t.sortCol], rows[j][t.sortCol]
+		c := compareCells(columnType, a, b)
+		if t.sortType == SortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	t.setDataRows(rows)
+	t.renumber()
+}
+
+// compareCells compares cells by their raw value. If either one does not have value, texts are compared.
+func compareCells(columnType ColumnType, a, b *cview.TableCell) int {
+	if a.Reference == nil || b.Reference == nil {
+		return strings.Compare(a.Text, b.Text)
+	}
+	return CompareValues(columnType, a.Reference, b.Reference)
+}
+
+// CompareValues compares two raw values of given type. It returns -1 if a < b, 0 if a == b and 1 if a > b.
+// Values that cannot be converted to type are compared as strings.
+func CompareValues(columnType ColumnType, a, b interface{}) int {
+	switch columnType {
+	case ColumnInt, ColumnBytes, ColumnDuration:
+		x, okA := toInt64(a)
+		y, okB := toInt64(b)
+		if okA && okB {
+			return compareInt(x, y)
+		}
+	case ColumnFloat:
+		x, okA := toFloat64(a)
+		y, okB := toFloat64(b)
+		if okA && okB {
+			if x < y {
+				return -1
+			} else if x > y {
+				return 1
+			}
+			return 0
+		}
+	case ColumnTime:
+		x, okA := a.(time.Time)
+		y, okB := b.(time.Time)
+		if okA && okB {
+			if x.Before(y) {
+				return -1
+			} else if x.After(y) {
+				return 1
+			}
+			return 0
+		}
+	case ColumnBool:
+		x, okA := a.(bool)
+		y, okB := b.(bool)
+		if okA && okB {
+			if x == y {
+				return 0
+			} else if !x {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareInt(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// formatValue formats value with column type and number format
+func formatValue(column *Column, format NumberFormat, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	switch column.Type {
+	case ColumnInt:
+		if v, ok := toInt64(value); ok {
+			return formatInt(v, format)
+		}
+	case ColumnFloat:
+		if v, ok := toFloat64(value); ok {
+			return formatFloat(v, column.Precision, format)
+		}
+	case ColumnDuration:
+		if v, ok := value.(time.Duration); ok {
+			return formatDuration(v)
+		}
+	case ColumnBytes:
+		if v, ok := toInt64(value); ok {
+			return formatBytes(v, format)
+		}
+	case ColumnTime:
+		if v, ok := value.(time.Time); ok {
+			if v.IsZero() {
+				return ""
+			}
+			layout := column.TimeLayout
+			if layout == "" {
+				layout = "2006-01-02 15:04:05"
+			}
+			return v.Format(layout)
+		}
+	case ColumnBool:
+		if v, ok := value.(bool); ok {
+			return strconv.FormatBool(v)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// formatInt formats integer with group separators
+func formatInt(value int64, format NumberFormat) string {
+	text := strconv.FormatInt(value, 10)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		text = text[1:]
+	}
+	return sign + groupDigits(text, format.GroupSeparator)
+}
+
+// formatFloat formats float with group & decimal separators. If precision is negative, shortest
+// representation is used.
+func formatFloat(value float64, precision int, format NumberFormat) string {
+	if precision < 0 {
+		precision = -1
+	}
+	text := strconv.FormatFloat(value, 'f', precision, 64)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign = "-"
+		text = text[1:]
+	}
+	parts := strings.SplitN(text, ".", 2)
+	text = sign + groupDigits(parts[0], format.GroupSeparator)
+	if len(parts) == 2 {
+		text += format.DecimalSeparator + parts[1]
+	}
+	return text
+}
+
+// formatDuration formats duration rounding it to be human-readable
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Minute || d <= -time.Minute:
+		return d.Round(time.Second).String()
+	case d >= time.Second || d <= -time.Second:
+		return d.Round(time.Millisecond * 10).String()
+	default:
+		return d.String()
+	}
+}
+
+// formatBytes formats size with binary prefixes, e.g. 1.5 KiB
+func formatBytes(size int64, format NumberFormat) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	value := float64(size)
+	unit := 0
+	for (value >= 1024 || value <= -1024) && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit > 0 && unit < len(units)-1 && math.Abs(math.Round(value*10)/10) >= 1024 {
+		// Rounding reached next unit, e.g. 1023.99 KiB
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return formatInt(size, format) + " " + units[0]
+	}
+	return formatFloat(value, 1, format) + " " + units[unit]
+}
+
+// groupDigits inserts separator between each group of three digits
+func groupDigits(digits string, separator string) string {
+	if separator == "" || len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	first := len(digits) % 3
+	if first > 0 {
+		b.WriteString(digits[:first])
+	}
+	for i := first; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case time.Duration:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		if i, ok := toInt64(value); ok {
+			return float64(i), true
+		}
+		return 0, false
+	}
+}
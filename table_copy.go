@@ -0,0 +1,108 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strings"
+)
+
+// SetCopyFunc sets function that is called whenever user copies something from table. Copied text is sent
+// to terminal with OSC 52 and osc52 tells whether screen was able to send it.
+// Use this as fallback for e.g. local clipboard tools.
+func (t *Table) SetCopyFunc(copyFunc func(text string, osc52 bool)) *Table {
+	t.clipboard.copyFunc = copyFunc
+	return t
+}
+
+// CopySelectedRows copies multi-selected rows, or selected row if there is no multi-selection,
+// to clipboard. Cells are separated with tabs and rows with newlines. Index column is not copied.
+// User can copy rows with 'y'.
+func (t *Table) CopySelectedRows() *Table {
+	indices := t.GetSelectedRows()
+	if len(indices) == 0 {
+		row, _ := t.Table.GetSelection()
+		index := t.rowIndex(row)
+		if index < 0 {
+			return t
+		}
+		indices = []int{index}
+	}
+	rows := make([]string, 0, len(indices))
+	for _, index := range indices {
+		if cells := t.indexCells(index); cells != nil {
+			rows = append(rows, t.rowText(cells))
+		}
+	}
+	t.clipboard.copy(strings.Join(rows, "\n"))
+	return t
+}
+
+// CopyCell copies text of a single cell to clipboard. Index is same as with AddRow.
+// User can copy selected cell with 'Y', if columns are selectable.
+func (t *Table) CopyCell(index, column int) *Table {
+	cells := t.indexCells(index)
+	if column < 0 || column >= len(cells) {
+		return t
+	}
+	t.clipboard.copy(t.cellText(cells, column))
+	return t
+}
+
+// copyInput handles keys for copying. Return true if event was handled.
+func (t *Table) copyInput(event *tcell.EventKey) bool {
+	if event.Key() != tcell.KeyRune {
+		return false
+	}
+	switch event.Rune() {
+	case 'y':
+		t.CopySelectedRows()
+	case 'Y':
+		if _, columns := t.Table.GetSelectable(); !columns {
+			return false
+		}
+		row, col := t.Table.GetSelection()
+		t.CopyCell(t.rowIndex(row), col)
+	default:
+		return false
+	}
+	return true
+}
+
+// rowText returns texts of row cells separated with tabs
+func (t *Table) rowText(cells []*cview.TableCell) string {
+	start := 0
+	if t.showIndex {
+		start = 1
+	}
+	texts := []string{}
+	for col := start; col < len(cells); col++ {
+		texts = append(texts, t.cellText(cells, col))
+	}
+	return strings.Join(texts, "\t")
+}
+
+// cellText returns text of cell in row as user sees it, without tree prefix
+func (t *Table) cellText(cells []*cview.TableCell, col int) string {
+	if t.tree != nil && col == t.treeColumn() {
+		if node := t.tree.nodes[cells[0]]; node != nil {
+			return t.nodeText(node)
+		}
+	}
+	return cells[col].Text
+}
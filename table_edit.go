@@ -0,0 +1,290 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strconv"
+)
+
+// EditorType is a type of input that is used to edit a cell.
+type EditorType int
+
+const (
+	// EditorText accepts any text
+	EditorText EditorType = iota
+	// EditorInteger accepts only integers
+	EditorInteger
+	// EditorEnum shows a dropdown of ColumnEditor.Options
+	EditorEnum
+	// EditorBool toggles value between 'true' and 'false' without opening an input
+	EditorBool
+)
+
+// ColumnEditor describes how cells in single column can be edited.
+type ColumnEditor struct {
+	Type EditorType
+	// Options are the values to choose from with EditorEnum.
+	Options []string
+	// Validate is called with new value before committing it. If it returns error,
+	// editor stays open and error is shown to user.
+	Validate func(value string) error
+}
+
+// cellEditor is the currently open inline editor
+type cellEditor struct {
+	row    int
+	column int
+	editor *ColumnEditor
+	input  *cview.InputField
+	list   *cview.List
+	err    string
+}
+
+// SetEditable enables editing cells. When enabled, table selects single cells instead of rows and
+// Enter or 'e' opens an editor for cells in columns that have a ColumnEditor set.
+func (t *Table) SetEditable(editable bool) *Table {
+	if editable == t.editable {
+		return t
+	}
+	t.editable = editable
+	row, _ := t.Table.GetSelection()
+	if _, columns := t.Table.GetSelectable(); row == 0 && columns && t.sortable() {
+		// Header is selected for sorting, keep selecting columns there
+		return t
+	}
+	t.setRowsSelectable()
+	return t
+}
+
+// SetColumnEditor sets editor for given column. If index is included as first column,
+// it must be included in column number. Nil editor disables editing the column.
+func (t *Table) SetColumnEditor(column int, editor *ColumnEditor) *Table {
+	if t.editors == nil {
+		t.editors = map[int]*ColumnEditor{}
+	}
+	if editor == nil {
+		delete(t.editors, column)
+	} else {
+		t.editors[column] = editor
+	}
+	return t
+}
+
+// SetEditFunc sets a function that gets called when user commits a new value to a cell.
+// Index is row index as in AddRow. If it returns error, the value is rejected and error is shown to user.
+// On success cell text is updated with value.
+func (t *Table) SetEditFunc(editFunc func(index, column int, value string) error) *Table {
+	t.editFunc = editFunc
+	return t
+}
+
+// IsEditing returns true if there's an editor or search prompt open.
+func (t *Table) IsEditing() bool {
+	return t.editor != nil || t.search != nil && t.search.input != nil
+}
+
+// setRowsSelectable sets data rows selectable. If table is editable, single cells are selected.
+func (t *Table) setRowsSelectable() {
+	t.Table.SetSelectable(true, t.editable)
+}
+
+// openEditor opens editor for cell at row, column. Return true if editor was opened
+// or the cell was toggled.
+func (t *Table) openEditor(row, column int) bool {
+	if !t.editable || row < 1 || t.groupAt(row) != nil {
+		return false
+	}
+	editor := t.editors[column]
+	if editor == nil {
+		return false
+	}
+	cell := t.Table.GetCell(row, column)
+
+	if editor.Type == EditorBool {
+		value, err := strconv.ParseBool(cell.Text)
+		if err != nil {
+			value = false
+		}
+		e := &cellEditor{row: row, column: column, editor: editor}
+		if !t.commitValue(e, strconv.FormatBool(!value)) {
+			t.editor = e
+		}
+		return true
+	}
+
+	e := &cellEditor{
+		row:    row,
+		column: column,
+		editor: editor,
+	}
+
+	if editor.Type == EditorEnum {
+		e.list = cview.NewList()
+		e.list.ShowSecondaryText(false)
+		e.list.SetBorder(true)
+		for i, v := range editor.Options {
+			e.list.AddItem(v, "", 0, nil)
+			if v == cell.Text {
+				e.list.SetCurrentItem(i)
+			}
+		}
+		e.list.Focus(nil)
+	} else {
+		e.input = cview.NewInputField()
+		e.input.SetText(cell.Text)
+		if editor.Type == EditorInteger {
+			e.input.SetAcceptanceFunc(cview.InputFieldInteger)
+		}
+		e.input.SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEnter {
+				t.commitEdit()
+			} else if key == tcell.KeyEscape {
+				t.cancelEdit()
+			}
+		})
+		e.input.Focus(nil)
+	}
+	t.editor = e
+	return true
+}
+
+// commitEdit tries to commit value currently in editor.
+func (t *Table) commitEdit() {
+	e := t.editor
+	if e == nil {
+		return
+	}
+	value := ""
+	if e.list != nil {
+		value, _ = e.list.GetItemText(e.list.GetCurrentItem())
+	} else if e.input != nil {
+		value = e.input.GetText()
+	}
+	if t.commitValue(e, value) {
+		t.editor = nil
+	}
+}
+
+// commitValue validates value and sets it to cell. If value is rejected, error is set to editor
+// and false returned.
+func (t *Table) commitValue(e *cellEditor, value string) bool {
+	var err error
+	if e.editor.Type == EditorInteger {
+		if _, parseErr := strconv.Atoi(value); parseErr != nil {
+			err = fmt.Errorf("not an integer: '%s'", value)
+		}
+	}
+	if err == nil && e.editor.Validate != nil {
+		err = e.editor.Validate(value)
+	}
+	if err == nil && t.editFunc != nil {
+		err = t.editFunc(t.rowIndex(e.row), e.column, value)
+	}
+	if err != nil {
+		e.err = err.Error()
+		return false
+	}
+
+	cell := t.Table.GetCell(e.row, e.column)
+	if t.column(e.column) != nil {
+		typed := t.parseValue(e.column, value)
+		cell.SetReference(typed)
+		value = t.formatValue(e.column, typed)
+	}
+	cell.SetText(value)
+	t.dataChanged()
+	return true
+}
+
+// cancelEdit closes editor without committing value.
+func (t *Table) cancelEdit() {
+	t.editor = nil
+}
+
+// editorInput passes event to open editor.
+func (t *Table) editorInput(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+	e := t.editor
+	// Keep focus in table, editor is not a standalone primitive
+	ignoreFocus := func(p cview.Primitive) {}
+
+	if e.input == nil && e.list == nil {
+		// Rejected toggle, any key dismisses error
+		t.editor = nil
+		return
+	}
+
+	if e.list != nil {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			t.commitEdit()
+		case tcell.KeyEscape:
+			t.cancelEdit()
+		default:
+			e.list.InputHandler()(event, ignoreFocus)
+		}
+		return
+	}
+	e.err = ""
+	e.input.InputHandler()(event, ignoreFocus)
+}
+
+// drawEditor draws editor on top of edited cell
+func (t *Table) drawEditor(screen tcell.Screen) {
+	e := t.editor
+	if e == nil {
+		return
+	}
+
+	cell := t.Table.GetCell(e.row, e.column)
+	x, y, width := cell.GetLastPosition()
+	_, _, _, height := t.GetInnerRect()
+	_, screenHeight := screen.Size()
+	if width < 10 {
+		width = 10
+	}
+
+	if e.input != nil {
+		e.input.SetFieldBackgroundColor(cview.Styles.ContrastBackgroundColor)
+		e.input.SetRect(x, y, width, 1)
+		e.input.Draw(screen)
+	} else if e.list != nil {
+		listWidth := width
+		for i := 0; i < e.list.GetItemCount(); i++ {
+			text, _ := e.list.GetItemText(i)
+			listWidth = max(listWidth, cview.TaggedStringWidth(text)+2)
+		}
+		listHeight := min(e.list.GetItemCount()+2, max(height-1, 3))
+		listY := y + 1
+		if listY+listHeight > screenHeight {
+			listY = max(0, y-listHeight)
+		}
+		e.list.SetRect(x, listY, listWidth, listHeight)
+		e.list.Draw(screen)
+	}
+
+	if e.err != "" {
+		errY := y + 1
+		if e.list != nil || errY >= screenHeight {
+			errY = y - 1
+		}
+		text := cview.Escape(e.err)
+		cview.Print(screen, text, x, errY, max(width, cview.TaggedStringWidth(text)), cview.AlignLeft, tcell.ColorRed)
+	}
+}
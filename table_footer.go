@@ -0,0 +1,280 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Aggregation computes single value from all values in a column. Values are raw values of cells
+// (see AddValues) or cell texts if cells have no raw value. Result is formatted with column type.
+type Aggregation func(values []interface{}) interface{}
+
+// AggregateSum sums numeric values. Result is int64 if all values are integers, time.Duration if
+// all values are durations and float64 otherwise.
+func AggregateSum(values []interface{}) interface{} {
+	var intSum int64
+	var floatSum float64
+	isInt := true
+	isDuration := len(values) > 0
+	for _, v := range values {
+		if _, ok := v.(time.Duration); !ok {
+			isDuration = false
+		}
+		if i, ok := toInt64(v); ok {
+			intSum += i
+			floatSum += float64(i)
+		} else if f, ok := aggregateFloat(v); ok {
+			isInt = false
+			floatSum += f
+		}
+	}
+	if isDuration {
+		return time.Duration(intSum)
+	}
+	if isInt {
+		return intSum
+	}
+	return floatSum
+}
+
+// AggregateAverage computes average of numeric values. Result is time.Duration if all values are durations
+// and float64 otherwise.
+func AggregateAverage(values []interface{}) interface{} {
+	count := 0
+	for _, v := range values {
+		if _, ok := aggregateFloat(v); ok {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	sum := AggregateSum(values)
+	if d, ok := sum.(time.Duration); ok {
+		return d / time.Duration(count)
+	}
+	f, _ := toFloat64(sum)
+	return f / float64(count)
+}
+
+// AggregateMin returns smallest value.
+func AggregateMin(values []interface{}) interface{} {
+	return aggregateCompare(values, -1)
+}
+
+// AggregateMax returns largest value.
+func AggregateMax(values []interface{}) interface{} {
+	return aggregateCompare(values, 1)
+}
+
+// AggregateCount returns number of non-empty values.
+func AggregateCount(values []interface{}) interface{} {
+	count := 0
+	for _, v := range values {
+		if v == nil || v == "" {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// aggregateCompare returns value that compares as want against all other values
+func aggregateCompare(values []interface{}, want int) interface{} {
+	var result interface{}
+	for _, v := range values {
+		if v == nil || v == "" {
+			continue
+		}
+		if result == nil || compareAny(v, result) == want {
+			result = v
+		}
+	}
+	return result
+}
+
+// compareAny compares values inferring their type.
+func compareAny(a, b interface{}) int {
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return CompareValues(ColumnTime, x, y)
+		}
+	}
+	x, okA := aggregateFloat(a)
+	y, okB := aggregateFloat(b)
+	if okA && okB {
+		return CompareValues(ColumnFloat, x, y)
+	}
+	return CompareValues(ColumnString, a, b)
+}
+
+// aggregateFloat converts number or numeric text to float
+func aggregateFloat(value interface{}) (float64, bool) {
+	if f, ok := toFloat64(value); ok {
+		return f, true
+	}
+	if text, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// SetColumnAggregation sets aggregation that is shown in footer row for column. If index is included as first
+// column, it must be included in column number. Nil aggregation removes it. Footer is shown if any column
+// has aggregation or footer label is set.
+func (t *Table) SetColumnAggregation(column int, aggregation Aggregation) *Table {
+	if t.aggregations == nil {
+		t.aggregations = map[int]Aggregation{}
+	}
+	if aggregation == nil {
+		delete(t.aggregations, column)
+	} else {
+		t.aggregations[column] = aggregation
+	}
+	t.footerDirty = true
+	return t
+}
+
+// SetFooterLabel sets text that is shown in first footer column, e.g. 'Total'. Aggregation in first
+// column overrides label.
+func (t *Table) SetFooterLabel(label string) *Table {
+	t.footerLabel = label
+	t.footerDirty = true
+	return t
+}
+
+// UpdateFooter recomputes footer aggregations and style rules. Table does this automatically when rows are
+// added, removed or edited, but if cells are modified directly, this needs to be called.
+func (t *Table) UpdateFooter() *Table {
+	t.dataChanged()
+	return t
+}
+
+// GetFooterCell returns footer cell for column, or nil if there's no footer.
+func (t *Table) GetFooterCell(column int) *cview.TableCell {
+	t.updateFooter()
+	if column < 0 || column >= len(t.footer) {
+		return nil
+	}
+	return t.footer[column]
+}
+
+// hasFooter returns true if footer row is shown
+func (t *Table) hasFooter() bool {
+	return len(t.aggregations) > 0 || t.footerLabel != ""
+}
+
+// updateFooter recomputes footer cells if needed
+func (t *Table) updateFooter() {
+	if !t.footerDirty || !t.hasFooter() {
+		return
+	}
+	t.footerDirty = false
+
+	columns := t.Table.GetColumnCount()
+	footer := make([]*cview.TableCell, columns)
+	for col := 0; col < columns; col++ {
+		text := ""
+		if col == 0 {
+			text = t.footerLabel
+		}
+		if aggregation, ok := t.aggregations[col]; ok {
+			text = t.formatAggregate(col, aggregation(t.columnValues(col)))
+		}
+		cell := cview.NewTableCell(text)
+		cell.SetAlign(t.Table.GetCell(0, col).Align)
+		cell.SetAttributes(tcell.AttrBold)
+		if t.addCellFunc != nil {
+			t.addCellFunc(cell, true, t.Table.GetRowCount())
+		}
+		footer[col] = cell
+	}
+	t.footer = footer
+}
+
+// formatAggregate formats aggregated value for column
+func (t *Table) formatAggregate(col int, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if column := t.column(col); column != nil {
+		if _, ok := value.(float64); ok && (column.Type == ColumnInt || column.Type == ColumnBytes) {
+			// E.g. average of integers
+			c := *column
+			c.Type = ColumnFloat
+			c.Precision = 2
+			return formatValue(&c, t.numberFormat, value)
+		}
+		return t.formatValue(col, value)
+	}
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return formatValue(&Column{}, t.numberFormat, value)
+}
+
+// columnValues returns raw value or text for each data row in column
+func (t *Table) columnValues(col int) []interface{} {
+	rows := t.allDataRows()
+	values := make([]interface{}, 0, len(rows))
+	for _, cells := range rows {
+		if col < len(cells) {
+			values = append(values, cellValue(cells[col]))
+		}
+	}
+	return values
+}
+
+// cellValue returns raw value of cell, or text if there is no raw value
+func cellValue(cell *cview.TableCell) interface{} {
+	if cell.Reference != nil {
+		return cell.Reference
+	}
+	return cell.Text
+}
+
+// drawFooter draws footer at the bottom of the table, aligned with header columns.
+func (t *Table) drawFooter(screen tcell.Screen, y int) {
+	t.updateFooter()
+	x, _, width, _ := t.GetInnerRect()
+
+	for col, cell := range t.footer {
+		header := t.Table.GetCell(0, col)
+		cellX, _, cellWidth := header.GetLastPosition()
+		if cellWidth <= 0 || cellX < x || cellX >= x+width {
+			// Column not visible
+			continue
+		}
+		style := tcell.StyleDefault.Foreground(cell.Color).Background(cell.BackgroundColor)
+		for i := -1; i < cellWidth && cellX+i < x+width; i++ {
+			if cellX+i >= x {
+				screen.SetContent(cellX+i, y, ' ', nil, style)
+			}
+		}
+		text := cell.Text
+		if cell.Attributes&tcell.AttrBold != 0 {
+			text = "[::b]" + text
+		}
+		cview.Print(screen, text, cellX, y, cellWidth, cell.Align, cell.Color)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"sort"
+)
+
+const arrowRight = "▶"
+
+// tableGroup is a group of rows sharing same value in group column
+type tableGroup struct {
+	value     string
+	key       *cview.TableCell
+	collapsed bool
+	// rows are stored here while group is collapsed
+	rows [][]*cview.TableCell
+}
+
+// SetGroupBy groups rows by value of column. If index is included as first column, it must be
+// included in column number. Each group gets a header row with group value and count of rows,
+// which can be collapsed and expanded with Enter or mouse click. Set column to -1 to disable grouping.
+// While grouping is enabled, AddRow appends rows to the end of table regardless of index,
+// and rows are moved to their groups on next draw. Row indices, e.g. in GetSelectedRows and GetValue,
+// count data rows in display order, including rows in collapsed groups, and skip group header rows.
+func (t *Table) SetGroupBy(column int) *Table {
+	if column < 0 {
+		t.ungroup()
+		t.groupBy = -1
+		if t.sortFunc == nil && t.columnTypes != nil {
+			// Restore order across groups
+			t.sortRows()
+		}
+		t.renumber()
+		return t
+	}
+	t.groupBy = column
+	t.regroup()
+	return t
+}
+
+// SetGroupSubtotals shows column aggregations (see SetColumnAggregation) computed over each group in
+// group header rows.
+func (t *Table) SetGroupSubtotals(enabled bool) *Table {
+	t.groupSubtotals = enabled
+	t.groupsDirty = true
+	return t
+}
+
+// SetGroupIndexRestart configures whether index column restarts from 1 in each group. If false,
+// rows are numbered globally.
+func (t *Table) SetGroupIndexRestart(restart bool) *Table {
+	t.groupIndexRestart = restart
+	t.groupsDirty = true
+	return t
+}
+
+// SetGroupCollapsed collapses or expands group with given value.
+func (t *Table) SetGroupCollapsed(value string, collapsed bool) *Table {
+	if t.collapsedGroups == nil {
+		t.collapsedGroups = map[string]bool{}
+	}
+	t.collapsedGroups[value] = collapsed
+	t.regroup()
+	return t
+}
+
+// grouped returns true if grouping is enabled
+func (t *Table) grouped() bool {
+	return t.groupBy >= 0
+}
+
+// groupAt returns group if row is a group header row, else nil
+func (t *Table) groupAt(row int) *tableGroup {
+	if !t.grouped() || row < 1 {
+		return nil
+	}
+	group, _ := t.Table.GetCell(row, 0).Reference.(*tableGroup)
+	return group
+}
+
+// toggleGroup collapses or expands group at row. Return true if row is a group header.
+func (t *Table) toggleGroup(row int) bool {
+	group := t.groupAt(row)
+	if group == nil {
+		return false
+	}
+	if t.collapsedGroups == nil {
+		t.collapsedGroups = map[string]bool{}
+	}
+	t.collapsedGroups[group.value] = !group.collapsed
+	t.regroup()
+
+	for i := 1; i < t.Table.GetRowCount(); i++ {
+		if g := t.groupAt(i); g != nil && g.value == group.value {
+			_, col := t.Table.GetSelection()
+			t.Table.Select(i, col)
+			break
+		}
+	}
+	return true
+}
+
+// ungroup removes group headers and puts all rows back to table
+func (t *Table) ungroup() {
+	if !t.grouped() {
+		return
+	}
+	t.setDataRows(t.allDataRows())
+}
+
+// renumber sets index column for each data row
+func (t *Table) renumber() {
+	if !t.showIndex {
+		return
+	}
+	index := t.indexOffset
+	for row := 1; row < t.Table.GetRowCount(); row++ {
+		if group := t.groupAt(row); group != nil {
+			if t.groupIndexRestart {
+				index = 0
+			} else {
+				index += len(group.rows)
+			}
+			continue
+		}
+		index++
+		cell := t.Table.GetCell(row, 0)
+		cell.SetText(fmt.Sprint(index))
+		cell.SetReference(index)
+	}
+}
+
+// regroup collects all rows to groups and rebuilds table with group headers.
+func (t *Table) regroup() {
+	t.groupsDirty = false
+	if !t.grouped() {
+		return
+	}
+	rows := t.allDataRows()
+
+	groups := []*tableGroup{}
+	byValue := map[string]*tableGroup{}
+	members := map[*tableGroup][][]*cview.TableCell{}
+	for _, cells := range rows {
+		if t.groupBy >= len(cells) {
+			continue
+		}
+		key := cells[t.groupBy]
+		group, ok := byValue[key.Text]
+		if !ok {
+			group = &tableGroup{
+				value:     key.Text,
+				key:       key,
+				collapsed: t.collapsedGroups[key.Text],
+			}
+			byValue[key.Text] = group
+			groups = append(groups, group)
+		}
+		members[group] = append(members[group], cells)
+	}
+
+	columnType := ColumnString
+	if column := t.column(t.groupBy); column != nil {
+		columnType = column.Type
+	}
+	descending := t.sortCol == t.groupBy && t.sortType == SortDesc
+	sort.SliceStable(groups, func(i, j int) bool {
+		c := compareCells(columnType, groups[i].key, groups[j].key)
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	out := make([][]*cview.TableCell, 0, len(rows)+len(groups))
+	for _, group := range groups {
+		groupRows := members[group]
+		group.rows = nil
+		out = append(out, t.groupHeaderCells(group, groupRows, len(out)+1))
+		if group.collapsed {
+			group.rows = groupRows
+		} else {
+			out = append(out, groupRows...)
+		}
+	}
+	t.setDataRows(out)
+	t.renumber()
+	t.dataChanged()
+}
+
+// groupHeaderCells creates header row cells for group at row
+func (t *Table) groupHeaderCells(group *tableGroup, rows [][]*cview.TableCell, row int) []*cview.TableCell {
+	labelCol := 0
+	if t.showIndex && len(t.columns) > 1 {
+		labelCol = 1
+	}
+
+	arrow := arrowDown
+	if group.collapsed {
+		arrow = arrowRight
+	}
+
+	cells := make([]*cview.TableCell, t.Table.GetColumnCount())
+	for col := range cells {
+		text := ""
+		if col == labelCol {
+			text = fmt.Sprintf("%s %s (%d)", arrow, group.value, len(rows))
+		} else if aggregation, ok := t.aggregations[col]; ok && t.groupSubtotals {
+			values := make([]interface{}, len(rows))
+			for i, cells := range rows {
+				values[i] = cellValue(cells[col])
+			}
+			text = t.formatAggregate(col, aggregation(values))
+		}
+		cell := cview.NewTableCell(text)
+		cell.SetReference(group)
+		cell.SetAttributes(tcell.AttrBold)
+		if col != labelCol {
+			cell.SetAlign(t.Table.GetCell(0, col).Align)
+		}
+		if t.addCellFunc != nil {
+			t.addCellFunc(cell, true, row)
+		}
+		cells[col] = cell
+	}
+	return cells
+}
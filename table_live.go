@@ -0,0 +1,311 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"time"
+)
+
+// animationInterval is how often table is redrawn while animating
+const animationInterval = time.Millisecond * 100
+
+// cellFlash is a highlight on changed cell
+type cellFlash struct {
+	color tcell.Color
+	start time.Time
+}
+
+// SetChangeHighlight sets colors that cells changed with UpdateRows or UpdateValues are highlighted with.
+// Cells whose numeric value went up are highlighted with up, ones that went down with down and other
+// changes with changed. Highlight fades back to normal background during duration.
+// Zero duration disables highlighting. Default duration is 2 seconds. Fading requires redraw func,
+// see SetRedrawFunc.
+func (t *Table) SetChangeHighlight(up, down, changed tcell.Color, duration time.Duration) *Table {
+	t.flashUp = up
+	t.flashDown = down
+	t.flashChanged = changed
+	t.flashDuration = duration
+	return t
+}
+
+// SetRedrawFunc sets function that table calls when it needs to be redrawn without user input,
+// e.g. while fading highlights. Function is called from a separate goroutine,
+// so it should be something like application.Draw.
+func (t *Table) SetRedrawFunc(redraw func()) *Table {
+	t.redrawFunc = redraw
+	return t
+}
+
+// UpdateRows replaces table content with rows identified by keys. Keys and rows must be of same length,
+// otherwise error is returned and table is not modified.
+// Rows with existing keys are patched in place and only changed cells are modified, new keys are added and
+// rows whose key is missing are removed. Rows are ordered as keys. Rows added without key with AddRow are
+// removed. Selected row and scroll offset are kept, and changed cells are highlighted, see SetChangeHighlight.
+func (t *Table) UpdateRows(keys []string, rows [][]string) error {
+	return t.updateRows(keys, rows, nil)
+}
+
+// UpdateValues is like UpdateRows, but with raw values that are formatted according to column types
+// set with SetTypedColumns.
+func (t *Table) UpdateValues(keys []string, rows [][]interface{}) error {
+	offset := 0
+	if t.showIndex {
+		offset = 1
+	}
+	texts := make([][]string, len(rows))
+	for i, values := range rows {
+		texts[i] = make([]string, len(values))
+		for col, v := range values {
+			texts[i][col] = t.formatValue(col+offset, v)
+		}
+	}
+	return t.updateRows(keys, texts, rows)
+}
+
+// GetRowKey returns key of row at index, or empty string if row was not added with UpdateRows or UpdateValues.
+func (t *Table) GetRowKey(index int) string {
+	cells := t.indexCells(index)
+	if cells == nil {
+		return ""
+	}
+	return t.rowKeys[cells[0]]
+}
+
+// updateRows patches table to contain given rows. If values is not nil, cells get raw values as references.
+func (t *Table) updateRows(keys []string, texts [][]string, values [][]interface{}) error {
+	if len(keys) != len(texts) || values != nil && len(keys) != len(values) {
+		return fmt.Errorf("got %d keys for %d rows", len(keys), len(texts))
+	}
+	cursor, cursorCol := t.Table.GetSelection()
+	rowOffset, colOffset := t.Table.GetOffset()
+	selectedKey, hasSelected := t.rowKeys[t.Table.GetCell(cursor, 0)]
+
+	existing := map[string][]*cview.TableCell{}
+	oldRows := t.allDataRows()
+	for _, cells := range oldRows {
+		if key, ok := t.rowKeys[cells[0]]; ok {
+			existing[key] = cells
+		}
+	}
+
+	offset := 0
+	if t.showIndex {
+		offset = 1
+	}
+
+	rows := make([][]*cview.TableCell, len(keys))
+	kept := map[*cview.TableCell]bool{}
+	for i, key := range keys {
+		var rowValues []interface{}
+		if values != nil {
+			rowValues = values[i]
+		}
+		cells, ok := existing[key]
+		if !ok || len(cells) < len(texts[i])+offset {
+			cells = t.newRowCells(i, texts[i], rowValues)
+			if t.rowKeys == nil {
+				t.rowKeys = map[*cview.TableCell]string{}
+			}
+			t.rowKeys[cells[0]] = key
+		} else {
+			for col, text := range texts[i] {
+				var value interface{} = text
+				if rowValues != nil {
+					value = rowValues[col]
+				}
+				t.patchCell(cells[col+offset], text, value, rowValues != nil)
+			}
+		}
+		delete(existing, key)
+		kept[cells[0]] = true
+		rows[i] = cells
+	}
+
+	for _, cells := range oldRows {
+		if kept[cells[0]] {
+			continue
+		}
+		t.forgetRow(cells)
+	}
+
+	t.setDataRows(rows)
+	t.renumber()
+	if t.grouped() {
+		t.regroup()
+	}
+	t.dataChanged()
+
+	if cursor < 1 {
+		return nil
+	}
+	row := -1
+	if hasSelected {
+		for i := 1; i < t.Table.GetRowCount(); i++ {
+			if key, ok := t.rowKeys[t.Table.GetCell(i, 0)]; ok && key == selectedKey {
+				row = i
+				break
+			}
+		}
+	}
+	if row < 0 {
+		// Selected row was removed, keep cursor in same position
+		row = min(cursor, t.Table.GetRowCount()-1)
+	}
+	if row >= 1 && row != cursor {
+		t.Table.Select(row, cursorCol)
+	}
+	t.Table.SetOffset(max(0, rowOffset+row-cursor), colOffset)
+	return nil
+}
+
+// forgetRow removes all state table has about row that is removed.
+func (t *Table) forgetRow(cells []*cview.TableCell) {
+	if len(cells) == 0 {
+		return
+	}
+	delete(t.rowKeys, cells[0])
+	delete(t.selectedRows, cells[0])
+	for _, cell := range cells {
+		delete(t.cellStyles, cell)
+		delete(t.flashes, cell)
+	}
+}
+
+// patchCell sets new text and value to cell and highlights it if it changed.
+func (t *Table) patchCell(cell *cview.TableCell, text string, value interface{}, setValue bool) {
+	if cell.Text != text {
+		t.flashCell(cell, cellValue(cell), value)
+		cell.SetText(text)
+	}
+	if setValue {
+		cell.SetReference(value)
+	}
+}
+
+// flashCell starts highlight on cell that changed from old to new value
+func (t *Table) flashCell(cell *cview.TableCell, old, new interface{}) {
+	if t.flashDuration <= 0 {
+		return
+	}
+	color := t.flashChanged
+	x, okOld := aggregateFloat(old)
+	y, okNew := aggregateFloat(new)
+	if okOld && okNew {
+		if y > x {
+			color = t.flashUp
+		} else if y < x {
+			color = t.flashDown
+		}
+	}
+	// Make sure original style is stored before highlighting
+	t.baseStyle(cell)
+	if t.flashes == nil {
+		t.flashes = map[*cview.TableCell]cellFlash{}
+	}
+	now := time.Now()
+	t.flashes[cell] = cellFlash{color: color, start: now}
+	t.animate(now.Add(t.flashDuration))
+}
+
+// flashColor returns background for cell that is highlighted
+func (t *Table) flashColor(flash cellFlash, background tcell.Color) tcell.Color {
+	if t.flashDuration <= 0 {
+		return background
+	}
+	ratio := float64(time.Since(flash.start)) / float64(t.flashDuration)
+	if ratio >= 1 {
+		return background
+	}
+	return blendColor(flash.color, background, ratio)
+}
+
+// updateFlashes restyles rows with highlighted cells and removes highlights that have faded.
+func (t *Table) updateFlashes() {
+	if len(t.flashes) == 0 {
+		return
+	}
+	for row := 1; row < t.Table.GetRowCount(); row++ {
+		flashing := false
+		for col := 0; col < t.Table.GetColumnCount(); col++ {
+			cell := t.Table.GetCell(row, col)
+			if flash, ok := t.flashes[cell]; ok {
+				flashing = true
+				if time.Since(flash.start) >= t.flashDuration {
+					delete(t.flashes, cell)
+				}
+			}
+		}
+		if flashing {
+			t.restyleRow(row)
+		}
+	}
+}
+
+// animate redraws table periodically until given time, if redraw func is set.
+func (t *Table) animate(until time.Time) {
+	if t.redrawFunc == nil {
+		return
+	}
+	t.animationLock.Lock()
+	if until.After(t.animateUntil) {
+		t.animateUntil = until
+	}
+	running := t.animating
+	t.animating = true
+	t.animationLock.Unlock()
+	if running {
+		return
+	}
+
+	redraw := t.redrawFunc
+	go func() {
+		ticker := time.NewTicker(animationInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			redraw()
+			t.animationLock.Lock()
+			done := now.After(t.animateUntil)
+			if done {
+				t.animating = false
+			}
+			t.animationLock.Unlock()
+			if done {
+				return
+			}
+		}
+	}()
+}
+
+// blendColor mixes from with to by ratio, 0 being from and 1 being to. Default color is treated as
+// default background color. If either color has no rgb value, from is returned.
+func blendColor(from, to tcell.Color, ratio float64) tcell.Color {
+	if to == tcell.ColorDefault {
+		to = cview.Styles.PrimitiveBackgroundColor
+	}
+	r1, g1, b1 := from.RGB()
+	r2, g2, b2 := to.RGB()
+	if r1 < 0 || r2 < 0 {
+		return from
+	}
+	mix := func(a, b int32) int32 {
+		return a + int32(float64(b-a)*ratio)
+	}
+	return tcell.NewRGBColor(mix(r1, r2), mix(g1, g2), mix(b1, b2))
+}
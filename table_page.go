@@ -0,0 +1,328 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strconv"
+	"sync"
+)
+
+// Page is a single page of rows, returned by PageFetchFunc.
+type Page struct {
+	// Rows are cell texts without index column.
+	Rows [][]string
+	// Values are raw values for typed columns, see SetTypedColumns. If set, Rows is ignored.
+	Values [][]interface{}
+	// Total is total number of rows in dataset, or -1 if it is not known.
+	Total int
+	// Err is shown in page indicator if fetching page failed.
+	Err error
+}
+
+// PageFetchFunc fetches rows for page. First page is 0. Function must not block, and it must call done
+// once page is fetched. Done can be called from any goroutine.
+type PageFetchFunc func(page, pageSize int, done func(page Page))
+
+// tablePager is state of pagination in table
+type tablePager struct {
+	fetch PageFetchFunc
+	// page is currently shown page
+	page int
+	// requested is page of latest request, or page if request failed
+	requested int
+	pageSize  int
+	sizes     []int
+	total     int
+	rows      int
+	loading   bool
+	err       string
+	sizeList  *cview.List
+
+	lock        sync.Mutex
+	request     int
+	pending     *Page
+	pendingPage int
+}
+
+// SetPageFetchFunc enables pagination. Table shows one page at a time and fetches pages with fetch.
+// Current page is shown below the table. User can change page with PgUp/PgDn or [ and ], and change page size
+// with 'p'. Index column shows position of row in whole dataset. Fetched page is shown on next draw,
+// so use SetRedrawFunc to have table redrawn once page is fetched.
+func (t *Table) SetPageFetchFunc(fetch PageFetchFunc, pageSize int) *Table {
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	t.pager = &tablePager{
+		fetch:    fetch,
+		pageSize: pageSize,
+		sizes:    []int{10, 25, 50, 100, 250},
+		total:    -1,
+	}
+	t.loadPage(0)
+	return t
+}
+
+// SetPageSizes sets page sizes that user can choose from.
+func (t *Table) SetPageSizes(sizes []int) *Table {
+	if t.pager != nil {
+		t.pager.sizes = sizes
+	}
+	return t
+}
+
+// SetPage fetches and shows given page.
+func (t *Table) SetPage(page int) *Table {
+	if t.pager != nil {
+		t.loadPage(page)
+	}
+	return t
+}
+
+// SetPageSize changes page size. Page is changed so that first row of current page stays visible.
+func (t *Table) SetPageSize(size int) *Table {
+	p := t.pager
+	if p == nil || size <= 0 {
+		return t
+	}
+	first := p.requested * p.pageSize
+	p.pageSize = size
+	t.loadPage(first / size)
+	return t
+}
+
+// GetPage returns current page, or -1 if pagination is not enabled.
+func (t *Table) GetPage() int {
+	if t.pager == nil {
+		return -1
+	}
+	return t.pager.page
+}
+
+// GetPageCount returns total number of pages, or -1 if it is not known.
+func (t *Table) GetPageCount() int {
+	p := t.pager
+	if p == nil || p.total < 0 {
+		return -1
+	}
+	return max(1, (p.total+p.pageSize-1)/p.pageSize)
+}
+
+// IsLoading returns true if table is waiting for page to be fetched.
+func (t *Table) IsLoading() bool {
+	return t.pager != nil && t.pager.loading
+}
+
+// ReloadPage fetches current page again.
+func (t *Table) ReloadPage() *Table {
+	if t.pager != nil {
+		t.loadPage(t.pager.requested)
+	}
+	return t
+}
+
+// loadPage requests page from fetch func. Old rows and page are shown until page is fetched.
+func (t *Table) loadPage(page int) {
+	p := t.pager
+	if page < 0 {
+		page = 0
+	}
+	p.requested = page
+	p.loading = true
+	p.err = ""
+
+	p.lock.Lock()
+	p.request++
+	request := p.request
+	p.pending = nil
+	p.lock.Unlock()
+
+	p.fetch(page, p.pageSize, func(result Page) {
+		p.lock.Lock()
+		if request == p.request {
+			p.pending = &result
+			p.pendingPage = page
+		}
+		p.lock.Unlock()
+		if t.redrawFunc != nil {
+			t.redrawFunc()
+		}
+	})
+}
+
+// applyPage shows fetched page, if there is one
+func (t *Table) applyPage() {
+	p := t.pager
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	result := p.pending
+	page := p.pendingPage
+	p.pending = nil
+	p.lock.Unlock()
+	if result == nil {
+		return
+	}
+
+	p.loading = false
+	if result.Err != nil {
+		p.err = result.Err.Error()
+		// Stay on current page
+		p.requested = p.page
+		return
+	}
+	p.page = page
+	p.total = result.Total
+
+	_, col := t.Table.GetSelection()
+	t.Clear(false)
+	t.indexOffset = p.page * p.pageSize
+	if result.Values != nil {
+		p.rows = len(result.Values)
+		for i, values := range result.Values {
+			t.AddValues(i, values...)
+		}
+	} else {
+		p.rows = len(result.Rows)
+		for i, texts := range result.Rows {
+			t.addRow(i, texts, nil)
+		}
+	}
+	if p.rows > 0 {
+		t.Table.Select(1, col)
+	}
+	t.Table.SetOffset(0, 0)
+}
+
+// hasNextPage returns true if there is a page after current page
+func (t *Table) hasNextPage() bool {
+	p := t.pager
+	if p.total >= 0 {
+		return (p.requested+1)*p.pageSize < p.total
+	}
+	// Total unknown, assume there is more as long as pages are full
+	return p.rows >= p.pageSize
+}
+
+// pageInput handles keys for changing page. Return true if event was handled.
+func (t *Table) pageInput(event *tcell.EventKey) bool {
+	p := t.pager
+	if p.sizeList != nil {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			size, _ := p.sizeList.GetItemText(p.sizeList.GetCurrentItem())
+			p.sizeList = nil
+			if n, err := strconv.Atoi(size); err == nil {
+				t.SetPageSize(n)
+			}
+		case tcell.KeyEscape:
+			p.sizeList = nil
+		default:
+			p.sizeList.InputHandler()(event, func(p cview.Primitive) {})
+		}
+		return true
+	}
+
+	key := event.Key()
+	if key == tcell.KeyRune {
+		switch event.Rune() {
+		case ']':
+			key = tcell.KeyPgDn
+		case '[':
+			key = tcell.KeyPgUp
+		case 'p':
+			t.openPageSizes()
+			return true
+		}
+	}
+	switch key {
+	case tcell.KeyPgDn:
+		if t.hasNextPage() {
+			t.loadPage(p.requested + 1)
+		}
+	case tcell.KeyPgUp:
+		if p.requested > 0 {
+			t.loadPage(p.requested - 1)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// openPageSizes opens list of page sizes
+func (t *Table) openPageSizes() {
+	p := t.pager
+	if len(p.sizes) == 0 {
+		return
+	}
+	p.sizeList = cview.NewList()
+	p.sizeList.ShowSecondaryText(false)
+	p.sizeList.SetBorder(true)
+	p.sizeList.SetTitle("Page size")
+	for i, size := range p.sizes {
+		p.sizeList.AddItem(strconv.Itoa(size), "", 0, nil)
+		if size == p.pageSize {
+			p.sizeList.SetCurrentItem(i)
+		}
+	}
+	p.sizeList.Focus(nil)
+}
+
+// pageText returns text for page indicator
+func (t *Table) pageText() string {
+	p := t.pager
+	text := fmt.Sprintf("Page %d", p.requested+1)
+	if pages := t.GetPageCount(); pages >= 0 {
+		text += fmt.Sprintf("/%d", pages)
+	}
+	text += fmt.Sprintf(" (%d/page)", p.pageSize)
+	if p.loading {
+		text = "Loading… " + text
+	}
+	if p.err != "" {
+		text = "[red]" + cview.Escape(p.err) + "[-] " + text
+	}
+	return text
+}
+
+// drawPager draws page indicator on row y and page size list, if open.
+func (t *Table) drawPager(screen tcell.Screen, y int) {
+	p := t.pager
+	if p == nil {
+		return
+	}
+	x, _, width, height := t.GetInnerRect()
+	text := " " + t.pageText() + " "
+	textWidth := min(cview.TaggedStringWidth(text), width)
+	textX := x + width - textWidth
+	style := tcell.StyleDefault.Background(cview.Styles.PrimitiveBackgroundColor)
+	for i := 0; i < textWidth; i++ {
+		screen.SetContent(textX+i, y, ' ', nil, style)
+	}
+	cview.Print(screen, text, textX, y, textWidth, cview.AlignLeft, cview.Styles.SecondaryTextColor)
+
+	if p.sizeList != nil {
+		listWidth := 14
+		listHeight := min(len(p.sizes)+2, max(height-1, 3))
+		p.sizeList.SetRect(max(x, x+width-listWidth), max(0, y-listHeight), listWidth, listHeight)
+		p.sizeList.Draw(screen)
+	}
+}
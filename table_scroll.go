@@ -0,0 +1,104 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+const (
+	arrowLeftSmall  = '◀'
+	arrowRightSmall = '▶'
+)
+
+// SetFrozenColumns freezes first n columns so that they stay visible while rest of the columns are
+// scrolled horizontally with Left/Right. If index is shown, it is included in n. E.g. to keep
+// index and name visible, use 2.
+func (t *Table) SetFrozenColumns(n int) *Table {
+	t.frozenColumns = max(0, n)
+	t.Table.SetFixed(1, t.frozenColumns)
+	return t
+}
+
+// GetFrozenColumns returns number of frozen columns.
+func (t *Table) GetFrozenColumns() int {
+	return t.frozenColumns
+}
+
+// hiddenColumns returns whether there are columns hidden on left or right side of table.
+// Must be called after drawing table.
+func (t *Table) hiddenColumns() (left, right bool) {
+	_, columnOffset := t.Table.GetOffset()
+	left = columnOffset > 0
+
+	_, _, width, height := t.Table.GetInnerRect()
+	rowCount := t.Table.GetRowCount()
+	if rowCount-1 > height-1 {
+		// Scroll bar
+		width--
+	}
+	rowOffset, _ := t.Table.GetOffset()
+	rows := []int{0}
+	for row := rowOffset + 1; row < rowCount && len(rows) < height; row++ {
+		rows = append(rows, row)
+	}
+
+	total := -1
+	for col := 0; col < t.Table.GetColumnCount(); col++ {
+		if col >= t.frozenColumns && col < t.frozenColumns+columnOffset {
+			continue
+		}
+		colWidth := 0
+		for _, row := range rows {
+			cell := t.Table.GetCell(row, col)
+			cellWidth := cview.TaggedStringWidth(cell.Text)
+			if cell.MaxWidth > 0 && cell.MaxWidth < cellWidth {
+				cellWidth = cell.MaxWidth
+			}
+			colWidth = max(colWidth, cellWidth)
+		}
+		total += colWidth + 1
+		if total > width {
+			right = true
+			break
+		}
+	}
+	return
+}
+
+// drawColumnIndicators draws arrows on header row if there are columns hidden on either side.
+func (t *Table) drawColumnIndicators(screen tcell.Screen) {
+	left, right := t.hiddenColumns()
+	if !left && !right {
+		return
+	}
+	x, y, width, _ := t.Table.GetInnerRect()
+	style := tcell.StyleDefault.Foreground(cview.Styles.TertiaryTextColor).Background(cview.Styles.PrimitiveBackgroundColor)
+	if left {
+		leftX := x
+		if t.frozenColumns > 0 {
+			_, columnOffset := t.Table.GetOffset()
+			cellX, _, _ := t.Table.GetCell(0, t.frozenColumns+columnOffset).GetLastPosition()
+			leftX = max(x, cellX-1)
+		}
+		screen.SetContent(leftX, y, arrowLeftSmall, nil, style)
+	}
+	if right {
+		screen.SetContent(x+width-1, y, arrowRightSmall, nil, style)
+	}
+}
@@ -0,0 +1,410 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// tagPattern matches color and region tags, same as in cview
+var tagPattern = regexp.MustCompile(`\[([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([lbdru]+|\-)?)?)?\]|\["([a-zA-Z0-9_,;: \-\.]*)"\]`)
+
+// tableSearch is state of search in table
+type tableSearch struct {
+	// enabled allows opening search prompt with '/'
+	enabled bool
+	// input is open search prompt, or nil
+	input   *cview.InputField
+	query   string
+	matches []searchMatch
+	current int
+	dirty   bool
+
+	text       tcell.Color
+	background tcell.Color
+	currentBg  tcell.Color
+}
+
+// searchMatch is a cell that contains search query
+type searchMatch struct {
+	row int
+	col int
+}
+
+// SetSearchEnabled allows user to search by pressing '/', which opens search prompt. Enter jumps to
+// first match and n and N jump to next and previous match. Search is disabled by default.
+func (t *Table) SetSearchEnabled(enabled bool) *Table {
+	s := t.searchState()
+	s.enabled = enabled
+	if !enabled {
+		s.input = nil
+	}
+	return t
+}
+
+// SetSearchColors sets colors for highlighting search matches. Current match is highlighted with
+// current background.
+func (t *Table) SetSearchColors(text, background, current tcell.Color) *Table {
+	s := t.searchState()
+	s.text = text
+	s.background = background
+	s.currentBg = current
+	return t
+}
+
+// SetSearchQuery highlights all case-insensitive matches of query in table cells. Empty query clears search.
+// While query is set, n and N jump to next and previous match.
+func (t *Table) SetSearchQuery(query string) *Table {
+	s := t.searchState()
+	s.query = query
+	s.current = -1
+	s.dirty = true
+	return t
+}
+
+// GetSearchQuery returns current search query.
+func (t *Table) GetSearchQuery() string {
+	if t.search == nil {
+		return ""
+	}
+	return t.search.query
+}
+
+// searchState returns search state, creating it if needed
+func (t *Table) searchState() *tableSearch {
+	if t.search == nil {
+		t.search = &tableSearch{
+			current:    -1,
+			text:       tcell.ColorBlack,
+			background: tcell.ColorYellow,
+			currentBg:  tcell.ColorOrange,
+		}
+	}
+	return t.search
+}
+
+// searchDataChanged marks search matches to be recomputed
+func (t *Table) searchDataChanged() {
+	if t.search != nil {
+		t.search.dirty = true
+	}
+}
+
+// searchKey handles keys that open search or move between matches. Return true if event was handled.
+func (t *Table) searchKey(event *tcell.EventKey) bool {
+	if t.search != nil && t.search.input != nil {
+		t.searchInput(event)
+		return true
+	}
+	if event.Key() != tcell.KeyRune {
+		return false
+	}
+	switch event.Rune() {
+	case '/':
+		if t.search == nil || !t.search.enabled {
+			return false
+		}
+		t.openSearch()
+		return true
+	case 'n', 'N':
+		if t.GetSearchQuery() == "" {
+			return false
+		}
+		t.jumpToMatch(event.Rune() == 'n', false)
+		return true
+	}
+	return false
+}
+
+// openSearch opens search prompt
+func (t *Table) openSearch() {
+	s := t.searchState()
+	s.input = cview.NewInputField()
+	s.input.SetLabel("/")
+	s.input.SetText(s.query)
+	s.input.SetChangedFunc(func(text string) {
+		t.SetSearchQuery(text)
+	})
+}
+
+// searchInput passes event to search prompt
+func (t *Table) searchInput(event *tcell.EventKey) {
+	s := t.search
+	switch event.Key() {
+	case tcell.KeyEnter:
+		s.input = nil
+		if s.query != "" {
+			t.jumpToMatch(true, true)
+		}
+	case tcell.KeyEscape:
+		s.input = nil
+		t.SetSearchQuery("")
+	default:
+		s.input.InputHandler()(event, func(p cview.Primitive) {})
+	}
+}
+
+// updateMatches finds all cells that match query
+func (t *Table) updateMatches() {
+	s := t.search
+	if s == nil || !s.dirty {
+		return
+	}
+	s.dirty = false
+	s.matches = nil
+	if s.query == "" {
+		return
+	}
+	for row := 1; row < t.Table.GetRowCount(); row++ {
+		if t.groupAt(row) != nil {
+			continue
+		}
+		for col := 0; col < t.Table.GetColumnCount(); col++ {
+			if len(findMatches(t.Table.GetCell(row, col).Text, s.query)) > 0 {
+				s.matches = append(s.matches, searchMatch{row: row, col: col})
+			}
+		}
+	}
+	if s.current >= len(s.matches) {
+		s.current = -1
+	}
+}
+
+// jumpToMatch selects next or previous match from selected cell. If inclusive, match in selected cell
+// is accepted.
+func (t *Table) jumpToMatch(forward, inclusive bool) {
+	t.updateMatches()
+	s := t.search
+	if len(s.matches) == 0 {
+		return
+	}
+	row, col := t.Table.GetSelection()
+	if s.current >= 0 && s.matches[s.current].row == row {
+		// Continue from current match when moving between columns of same row
+		col = s.matches[s.current].col
+	} else if !forward {
+		col = t.Table.GetColumnCount()
+	} else {
+		col = -1
+	}
+
+	next := -1
+	for i, m := range s.matches {
+		after := m.row > row || m.row == row && (m.col > col || inclusive && m.col == col)
+		if forward && after {
+			next = i
+			break
+		}
+		before := m.row < row || m.row == row && (m.col < col || inclusive && m.col == col)
+		if !forward && before {
+			next = i
+		}
+	}
+	if next < 0 {
+		// Wrap around
+		next = 0
+		if !forward {
+			next = len(s.matches) - 1
+		}
+	}
+
+	s.current = next
+	match := s.matches[next]
+	_, selectedCol := t.Table.GetSelection()
+	t.Table.Select(match.row, selectedCol)
+
+	// Scroll column into view if it was not visible
+	rowOffset, colOffset := t.Table.GetOffset()
+	if match.col >= t.frozenColumns {
+		if _, _, width := t.Table.GetCell(match.row, match.col).GetLastPosition(); width <= 0 ||
+			match.col < t.frozenColumns+colOffset {
+			t.Table.SetOffset(rowOffset, match.col-t.frozenColumns)
+		}
+	}
+}
+
+// highlightMatches sets highlighted text to visible matching cells. Returned function restores
+// original texts.
+func (t *Table) highlightMatches() func() {
+	t.updateMatches()
+	s := t.search
+	if s == nil || len(s.matches) == 0 {
+		return func() {}
+	}
+
+	// Table might scroll to selection while drawing
+	rowOffset, _ := t.Table.GetOffset()
+	cursor, _ := t.Table.GetSelection()
+	_, _, _, height := t.GetInnerRect()
+	first := min(rowOffset+1, cursor-height)
+	last := max(rowOffset+height, cursor+height)
+
+	type original struct {
+		cell *cview.TableCell
+		text string
+	}
+	originals := []original{}
+	for i, m := range s.matches {
+		if m.row < first || m.row > last {
+			continue
+		}
+		cell := t.Table.GetCell(m.row, m.col)
+		background := s.background
+		if i == s.current {
+			background = s.currentBg
+		}
+		originals = append(originals, original{cell: cell, text: cell.Text})
+		cell.Text = highlightText(cell.Text, s.query, cell.MaxWidth, s.text, background)
+	}
+	return func() {
+		for _, o := range originals {
+			o.cell.Text = o.text
+		}
+	}
+}
+
+// drawSearch draws search prompt at the bottom of table
+func (t *Table) drawSearch(screen tcell.Screen) {
+	s := t.search
+	if s == nil || s.input == nil {
+		return
+	}
+	x, y, width, height := t.GetInnerRect()
+	if height < 1 {
+		return
+	}
+	y += height - 1
+
+	t.updateMatches()
+	count := ""
+	if s.query != "" {
+		count = fmt.Sprintf(" %d matches", len(s.matches))
+	}
+	countWidth := len(count)
+	s.input.SetFieldBackgroundColor(cview.Styles.ContrastBackgroundColor)
+	s.input.SetRect(x, y, max(width-countWidth, 1), 1)
+	s.input.Draw(screen)
+	if countWidth > 0 && width > countWidth {
+		style := tcell.StyleDefault.Background(cview.Styles.PrimitiveBackgroundColor)
+		for i := 0; i < countWidth; i++ {
+			screen.SetContent(x+width-countWidth+i, y, ' ', nil, style)
+		}
+		cview.Print(screen, count, x+width-countWidth, y, countWidth, cview.AlignLeft, cview.Styles.SecondaryTextColor)
+	}
+}
+
+// findMatches returns start and end rune indices of all case-insensitive matches of query in text.
+// Color and region tags in text are skipped.
+func findMatches(text, query string) [][2]int {
+	if query == "" {
+		return nil
+	}
+	runes, positions := untaggedRunes(text)
+	pattern := []rune(query)
+	var matches [][2]int
+	for i := 0; i+len(pattern) <= len(runes); {
+		found := true
+		for j, r := range pattern {
+			if unicode.ToLower(runes[i+j]) != unicode.ToLower(r) {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, [2]int{positions[i], positions[i+len(pattern)-1] + 1})
+			i += len(pattern)
+		} else {
+			i++
+		}
+	}
+	return matches
+}
+
+// untaggedRunes returns runes of text without tags and index of each rune in original text.
+func untaggedRunes(text string) ([]rune, []int) {
+	tags := tagPattern.FindAllStringIndex(text, -1)
+	runes := make([]rune, 0, len(text))
+	positions := make([]int, 0, len(text))
+	index := 0
+	for i, r := range text {
+		for len(tags) > 0 && i >= tags[0][1] {
+			tags = tags[1:]
+		}
+		if len(tags) == 0 || i < tags[0][0] {
+			runes = append(runes, r)
+			positions = append(positions, index)
+		}
+		index++
+	}
+	return runes, positions
+}
+
+// highlightText wraps all matches of query in text with color tags. If maxWidth > 0 and first match
+// would be truncated, beginning of text is replaced with ellipsis so that match is visible.
+func highlightText(text, query string, maxWidth int, color, background tcell.Color) string {
+	matches := findMatches(text, query)
+	if len(matches) == 0 {
+		return text
+	}
+	runes := []rune(text)
+
+	start := 0
+	prefix := ""
+	end := matches[0][1]
+	limit := maxWidth
+	if end < len(runes) {
+		// Table replaces last visible character with ellipsis
+		limit--
+	}
+	if maxWidth > 0 && cview.TaggedStringWidth(string(runes[:end])) > limit {
+		start = matches[0][0]
+		for start > 0 && cview.TaggedStringWidth(string(runes[start-1:end]))+1 <= limit {
+			start--
+		}
+		prefix = string(cview.SemigraphicsHorizontalEllipsis)
+	}
+
+	tag := fmt.Sprintf("[%s:%s]", colorTag(color), colorTag(background))
+	var b strings.Builder
+	b.WriteString(prefix)
+	pos := start
+	for _, m := range matches {
+		if m[0] < start {
+			continue
+		}
+		b.WriteString(string(runes[pos:m[0]]))
+		b.WriteString(tag)
+		b.WriteString(string(runes[m[0]:m[1]]))
+		b.WriteString("[-:-]")
+		pos = m[1]
+	}
+	b.WriteString(string(runes[pos:]))
+	return b.String()
+}
+
+// colorTag returns color in format that can be used in color tags
+func colorTag(color tcell.Color) string {
+	if color == tcell.ColorDefault || color.Hex() < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("#%06x", color.Hex())
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// cellStyle is the original style of a cell before table changed it
+type cellStyle struct {
+	text       tcell.Color
+	background tcell.Color
+	attributes tcell.AttrMask
+}
+
+// SetMultiSelect enables selecting multiple rows. Space toggles current row, Shift+Up/Down
+// extends selection and mouse click with Ctrl / Shift toggles / selects range of rows.
+// Disabling multi-select clears selected rows.
+func (t *Table) SetMultiSelect(enabled bool) *Table {
+	t.multiSelect = enabled
+	if !enabled {
+		t.ClearSelectedRows()
+	}
+	return t
+}
+
+// SetMultiSelectColors sets colors for rows that are selected with multi-select.
+func (t *Table) SetMultiSelectColors(text, background tcell.Color) *Table {
+	t.multiSelectText = text
+	t.multiSelectBackground = background
+	for row := 1; row < t.Table.GetRowCount(); row++ {
+		if t.isRowSelected(row) {
+			t.restyleRow(row)
+		}
+	}
+	return t
+}
+
+// GetSelectedRows returns indices of rows selected with multi-select in ascending order.
+// Indices are same as with AddRow. Group header rows are not counted.
+func (t *Table) GetSelectedRows() []int {
+	indices := make([]int, 0, len(t.selectedRows))
+	if len(t.selectedRows) == 0 {
+		return indices
+	}
+	for index, cells := range t.allDataRows() {
+		if t.selectedRows[cells[0]] {
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+// SetSelectedRows replaces multi-selected rows with given indices. Indices are same as with AddRow.
+func (t *Table) SetSelectedRows(indices []int) *Table {
+	t.ClearSelectedRows()
+	if t.selectedRows == nil {
+		t.selectedRows = map[*cview.TableCell]bool{}
+	}
+	for _, index := range indices {
+		if cells := t.indexCells(index); cells != nil {
+			t.selectedRows[cells[0]] = true
+			t.restyleRow(t.indexRow(index))
+		}
+	}
+	return t
+}
+
+// ClearSelectedRows deselects all rows selected with multi-select.
+func (t *Table) ClearSelectedRows() *Table {
+	selected := t.selectedRows
+	t.selectedRows = map[*cview.TableCell]bool{}
+	for row := 1; row < t.Table.GetRowCount() && len(selected) > 0; row++ {
+		if selected[t.Table.GetCell(row, 0)] {
+			t.restyleRow(row)
+		}
+	}
+	t.selectAnchor = 0
+	return t
+}
+
+// isRowSelected returns true if row is selected with multi-select
+func (t *Table) isRowSelected(row int) bool {
+	if row < 1 || len(t.selectedRows) == 0 {
+		return false
+	}
+	return t.selectedRows[t.Table.GetCell(row, 0)]
+}
+
+// selectRow sets row selected or not. Rows are identified with their first cell, so that
+// selection persists when rows are moved.
+func (t *Table) selectRow(row int, selected bool) {
+	if row < 1 || row >= t.Table.GetRowCount() || t.groupAt(row) != nil {
+		return
+	}
+	if t.selectedRows == nil {
+		t.selectedRows = map[*cview.TableCell]bool{}
+	}
+	cell := t.Table.GetCell(row, 0)
+	if selected {
+		t.selectedRows[cell] = true
+	} else {
+		delete(t.selectedRows, cell)
+	}
+	t.restyleRow(row)
+}
+
+// selectRange selects or deselects all rows between from and to, inclusive
+func (t *Table) selectRange(from, to int, selected bool) {
+	if from > to {
+		from, to = to, from
+	}
+	for row := from; row <= to; row++ {
+		t.selectRow(row, selected)
+	}
+}
+
+// multiSelectInput handles multi-select keys. Return true if event was handled.
+func (t *Table) multiSelectInput(event *tcell.EventKey) bool {
+	row, col := t.Table.GetSelection()
+	if row < 1 {
+		return false
+	}
+	key := event.Key()
+	if key == tcell.KeyRune && event.Rune() == ' ' {
+		t.selectRow(row, !t.isRowSelected(row))
+		t.selectAnchor = row
+		return true
+	}
+
+	if event.Modifiers()&tcell.ModShift == 0 || (key != tcell.KeyUp && key != tcell.KeyDown) {
+		return false
+	}
+	if t.selectAnchor < 1 {
+		t.selectAnchor = row
+	} else {
+		// Shrink range if cursor moves back towards anchor
+		t.selectRange(t.selectAnchor, row, false)
+	}
+	if key == tcell.KeyUp {
+		row = max(1, row-1)
+	} else {
+		row = min(t.Table.GetRowCount()-1, row+1)
+	}
+	t.Table.Select(row, col)
+	t.selectRange(t.selectAnchor, row, true)
+	return true
+}
+
+// multiSelectClick handles clicking row with modifiers
+func (t *Table) multiSelectClick(row int, modifiers tcell.ModMask) {
+	if row < 1 {
+		return
+	}
+	if modifiers&tcell.ModShift != 0 && t.selectAnchor > 0 {
+		t.selectRange(t.selectAnchor, row, true)
+	} else if modifiers&tcell.ModCtrl != 0 {
+		t.selectRow(row, !t.isRowSelected(row))
+		t.selectAnchor = row
+	} else {
+		t.selectAnchor = row
+	}
+}
+
+// baseStyle returns style cell had before table modified it.
+func (t *Table) baseStyle(cell *cview.TableCell) cellStyle {
+	if style, ok := t.cellStyles[cell]; ok {
+		return style
+	}
+	style := cellStyle{
+		text:       cell.Color,
+		background: cell.BackgroundColor,
+		attributes: cell.Attributes,
+	}
+	if t.cellStyles == nil {
+		t.cellStyles = map[*cview.TableCell]cellStyle{}
+	}
+	t.cellStyles[cell] = style
+	return style
+}
+
+// restyleRow resets row cells to their base style and applies style rules, multi-select highlight
+// and change highlight.
+func (t *Table) restyleRow(row int) {
+	if row < 1 || row >= t.Table.GetRowCount() || t.groupAt(row) != nil {
+		return
+	}
+	selected := t.isRowSelected(row)
+	cursor, _ := t.Table.GetSelection()
+	index := -1
+	if len(t.styleRules) > 0 {
+		index = t.rowIndex(row)
+	}
+	for col := 0; col < t.Table.GetColumnCount(); col++ {
+		cell := t.Table.GetCell(row, col)
+		flash, flashing := t.flashes[cell]
+		if _, ok := t.cellStyles[cell]; !ok && !selected && !flashing && len(t.styleRules) == 0 {
+			// Never modified
+			continue
+		}
+		style := t.baseStyle(cell)
+		if len(t.styleRules) > 0 {
+			style = t.applyStyleRules(style, StyleContext{
+				Index:         index,
+				Column:        col,
+				Value:         cellValue(cell),
+				Text:          cell.Text,
+				Selected:      row == cursor,
+				MultiSelected: selected,
+				table:         t,
+			})
+		}
+		if selected {
+			style.text = t.multiSelectText
+			style.background = t.multiSelectBackground
+		}
+		if flashing {
+			style.background = t.flashColor(flash, style.background)
+		}
+		cell.SetTextColor(style.text)
+		cell.SetBackgroundColor(style.background)
+		cell.SetAttributes(style.attributes)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+// StyleContext describes a cell that style rule is evaluated for.
+type StyleContext struct {
+	// Index is row index, same as with AddRow
+	Index int
+	// Column includes index column, if shown
+	Column int
+	// Value is raw value of the cell, or text if there's no raw value
+	Value interface{}
+	Text  string
+	// Selected is true if row is currently selected
+	Selected bool
+	// MultiSelected is true if row is selected with multi-select
+	MultiSelected bool
+
+	table *Table
+}
+
+// ColumnMax returns largest value in the column.
+func (c StyleContext) ColumnMax() interface{} {
+	return c.table.stats(c.Column).max
+}
+
+// ColumnMin returns smallest value in the column.
+func (c StyleContext) ColumnMin() interface{} {
+	return c.table.stats(c.Column).min
+}
+
+// StyleRule computes style for a cell. If rule does not apply, it returns false. Returned style is overlaid
+// on top of cell's own style: colors that are tcell.ColorDefault and empty attributes leave cell unchanged.
+// Rules are re-evaluated every time table data changes or row is selected or deselected.
+type StyleRule func(ctx StyleContext) (tcell.Style, bool)
+
+// Threshold is a style that is applied to values greater than or equal to Value.
+type Threshold struct {
+	Value float64
+	Style tcell.Style
+}
+
+// RuleMatch applies style to cells in column whose value matches. If column is -1, rule applies to all columns.
+func RuleMatch(column int, match func(value interface{}) bool, style tcell.Style) StyleRule {
+	return func(ctx StyleContext) (tcell.Style, bool) {
+		if column >= 0 && ctx.Column != column {
+			return style, false
+		}
+		return style, match(ctx.Value)
+	}
+}
+
+// RuleZebra applies style to every other row.
+func RuleZebra(style tcell.Style) StyleRule {
+	return func(ctx StyleContext) (tcell.Style, bool) {
+		return style, ctx.Index%2 == 1
+	}
+}
+
+// RuleMax applies style to cells in column that have the largest value in column.
+func RuleMax(column int, style tcell.Style) StyleRule {
+	return func(ctx StyleContext) (tcell.Style, bool) {
+		if ctx.Column != column {
+			return style, false
+		}
+		max := ctx.ColumnMax()
+		return style, max != nil && compareAny(ctx.Value, max) == 0
+	}
+}
+
+// RuleMin applies style to cells in column that have the smallest value in column.
+func RuleMin(column int, style tcell.Style) StyleRule {
+	return func(ctx StyleContext) (tcell.Style, bool) {
+		if ctx.Column != column {
+			return style, false
+		}
+		min := ctx.ColumnMin()
+		return style, min != nil && compareAny(ctx.Value, min) == 0
+	}
+}
+
+// RuleThresholds applies style of the highest threshold that numeric value in column is greater than or
+// equal to. Thresholds must be in ascending order.
+func RuleThresholds(column int, thresholds []Threshold) StyleRule {
+	return func(ctx StyleContext) (tcell.Style, bool) {
+		if ctx.Column != column {
+			return tcell.StyleDefault, false
+		}
+		value, ok := aggregateFloat(ctx.Value)
+		if !ok {
+			return tcell.StyleDefault, false
+		}
+		for i := len(thresholds) - 1; i >= 0; i-- {
+			if value >= thresholds[i].Value {
+				return thresholds[i].Style, true
+			}
+		}
+		return tcell.StyleDefault, false
+	}
+}
+
+// AddStyleRule adds a rule for styling cells. Rules are applied in the order they are added.
+func (t *Table) AddStyleRule(rule StyleRule) *Table {
+	t.styleRules = append(t.styleRules, rule)
+	t.stylesDirty = true
+	return t
+}
+
+// ClearStyleRules removes all style rules and restores cells to their original style.
+func (t *Table) ClearStyleRules() *Table {
+	t.styleRules = nil
+	t.stylesDirty = true
+	return t
+}
+
+// columnStats are cached min and max values of a column
+type columnStats struct {
+	min interface{}
+	max interface{}
+}
+
+// stats returns cached column stats
+func (t *Table) stats(col int) *columnStats {
+	if stats, ok := t.columnStats[col]; ok {
+		return stats
+	}
+	if t.columnStats == nil {
+		t.columnStats = map[int]*columnStats{}
+	}
+	values := t.columnValues(col)
+	stats := &columnStats{
+		min: AggregateMin(values),
+		max: AggregateMax(values),
+	}
+	t.columnStats[col] = stats
+	return stats
+}
+
+// applyStyleRules overlays all matching rules on style
+func (t *Table) applyStyleRules(style cellStyle, ctx StyleContext) cellStyle {
+	for _, rule := range t.styleRules {
+		ruleStyle, ok := rule(ctx)
+		if !ok {
+			continue
+		}
+		fg, bg, attr := ruleStyle.Decompose()
+		if fg != tcell.ColorDefault {
+			style.text = fg
+		}
+		if bg != tcell.ColorDefault {
+			style.background = bg
+		}
+		style.attributes |= attr
+	}
+	return style
+}
+
+// updateStyles restyles rows whose style might have changed since last draw.
+func (t *Table) updateStyles() {
+	row, _ := t.Table.GetSelection()
+	if t.stylesDirty {
+		t.stylesDirty = false
+		for i := 1; i < t.Table.GetRowCount(); i++ {
+			t.restyleRow(i)
+		}
+	} else if row != t.styledSelection && len(t.styleRules) > 0 {
+		t.restyleRow(t.styledSelection)
+		t.restyleRow(row)
+	}
+	t.styledSelection = row
+	t.updateFlashes()
+}
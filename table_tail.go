@@ -0,0 +1,165 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+)
+
+// SetFollow enables follow mode, where table selects last row whenever rows are appended,
+// as long as last row is selected. If user moves selection up, following is paused and
+// count of new rows is shown at the bottom of table. Following resumes when user selects last row
+// again, e.g. with G or End.
+func (t *Table) SetFollow(enabled bool) *Table {
+	t.follow = enabled
+	t.following = enabled
+	t.followRow = -1
+	t.newRows = 0
+	t.rowsAdded = 0
+	return t
+}
+
+// IsFollowing returns true if follow mode is enabled and not paused.
+func (t *Table) IsFollowing() bool {
+	return t.follow && t.following
+}
+
+// SetRowLimit sets maximum number of data rows in table. When rows are added beyond limit, rows
+// are removed from top of the table. Index column keeps counting from removed rows, so each row keeps
+// its number. While limit is set, AddRow appends rows to the end of table regardless of index.
+// Zero disables limit.
+func (t *Table) SetRowLimit(limit int) *Table {
+	t.rowLimit = limit
+	t.limitRows()
+	return t
+}
+
+// AppendRow adds row to the end of table.
+func (t *Table) AppendRow(content ...string) *Table {
+	t.addRow(t.Table.GetRowCount()-1, content, nil)
+	return t
+}
+
+// AppendValues adds row of raw values to the end of table, see AddValues.
+func (t *Table) AppendValues(values ...interface{}) *Table {
+	return t.AddValues(t.Table.GetRowCount()-1, values...)
+}
+
+// limitRows removes rows from top of the table until there are at most rowLimit rows.
+func (t *Table) limitRows() {
+	if t.rowLimit <= 0 {
+		return
+	}
+	if t.grouped() {
+		rows := t.allDataRows()
+		if len(rows) <= t.rowLimit {
+			return
+		}
+		dropped := len(rows) - t.rowLimit
+		for _, cells := range rows[:dropped] {
+			t.forgetRow(cells)
+		}
+		t.indexOffset += dropped
+		t.setDataRows(rows[dropped:])
+		t.groupsDirty = true
+		return
+	}
+
+	dropped := t.Table.GetRowCount() - 1 - t.rowLimit
+	if dropped <= 0 {
+		return
+	}
+	cursor, col := t.Table.GetSelection()
+	rowOffset, colOffset := t.Table.GetOffset()
+	for i := 0; i < dropped; i++ {
+		cells := make([]*cview.TableCell, t.Table.GetColumnCount())
+		for col := range cells {
+			cells[col] = t.Table.GetCell(1, col)
+		}
+		t.forgetRow(cells)
+		t.Table.RemoveRow(1)
+	}
+	t.indexOffset += dropped
+	if t.selectAnchor > 0 {
+		t.selectAnchor = max(1, t.selectAnchor-dropped)
+	}
+	if t.followRow > 0 {
+		t.followRow = max(1, t.followRow-dropped)
+	}
+	if cursor > 0 {
+		// Keep same row selected
+		t.Table.Select(max(1, cursor-dropped), col)
+		t.Table.SetOffset(max(0, rowOffset-dropped), colOffset)
+	}
+	t.dataChanged()
+}
+
+// updateFollow selects last row if table is following, else counts new rows.
+func (t *Table) updateFollow() {
+	if !t.follow {
+		return
+	}
+	added := t.rowsAdded
+	t.rowsAdded = 0
+
+	count := t.Table.GetRowCount()
+	cursor, col := t.Table.GetSelection()
+	if count < 2 {
+		return
+	}
+	if t.following && t.followRow >= 0 && cursor != t.followRow {
+		// User moved selection
+		t.following = false
+	} else if !t.following && cursor == count-1 {
+		t.following = true
+	}
+
+	if t.following {
+		t.newRows = 0
+		if cursor != count-1 {
+			t.Table.Select(count-1, col)
+		}
+		t.followRow = count - 1
+	} else {
+		t.newRows += added
+	}
+}
+
+// drawNewRowsIndicator draws count of new rows while following is paused
+func (t *Table) drawNewRowsIndicator(screen tcell.Screen) {
+	if !t.follow || t.following || t.newRows == 0 {
+		return
+	}
+	x, y, width, height := t.GetInnerRect()
+	if height < 2 {
+		return
+	}
+	y += height - 1
+
+	text := fmt.Sprintf(" %d new rows ", t.newRows)
+	if t.newRows == 1 {
+		text = " 1 new row "
+	}
+	textWidth := min(len(text), width)
+	style := tcell.StyleDefault.Background(cview.Styles.ContrastBackgroundColor)
+	for i := 0; i < textWidth; i++ {
+		screen.SetContent(x+width-textWidth+i, y, ' ', nil, style)
+	}
+	cview.Print(screen, text, x+width-textWidth, y, textWidth, cview.AlignLeft, cview.Styles.PrimaryTextColor)
+}
@@ -0,0 +1,938 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_formatValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		column Column
+		format NumberFormat
+		value  interface{}
+		want   string
+	}{
+		{"int", Column{Type: ColumnInt}, NumberFormatPlain, 1234567, "1234567"},
+		{"int grouped", Column{Type: ColumnInt}, NumberFormatEnglish, 1234567, "1,234,567"},
+		{"int negative", Column{Type: ColumnInt}, NumberFormatGerman, int64(-1234), "-1.234"},
+		{"int short", Column{Type: ColumnInt}, NumberFormatEnglish, uint8(255), "255"},
+		{"float", Column{Type: ColumnFloat, Precision: 2}, NumberFormatGerman, 1234.5, "1.234,50"},
+		{"float shortest", Column{Type: ColumnFloat, Precision: -1}, NumberFormatEnglish, 0.25, "0.25"},
+		{"float whole", Column{Type: ColumnFloat}, NumberFormatEnglish, 1234.5678, "1,235"},
+		{"bytes", Column{Type: ColumnBytes}, NumberFormatPlain, 1536, "1.5 KiB"},
+		{"bytes small", Column{Type: ColumnBytes}, NumberFormatPlain, 512, "512 B"},
+		{"bytes rounded to next unit", Column{Type: ColumnBytes}, NumberFormatPlain, 1024*1024 - 1, "1.0 MiB"},
+		{"bytes large", Column{Type: ColumnBytes}, NumberFormatSI, int64(5 * 1024 * 1024 * 1024), "5,0 GiB"},
+		{"duration", Column{Type: ColumnDuration}, NumberFormatPlain, time.Minute + 1500*time.Millisecond, "1m2s"},
+		{"time", Column{Type: ColumnTime, TimeLayout: "2006-01-02"}, NumberFormatPlain,
+			time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC), "2020-03-04"},
+		{"bool", Column{Type: ColumnBool}, NumberFormatPlain, true, "true"},
+		{"wrong type", Column{Type: ColumnInt}, NumberFormatPlain, "abc", "abc"},
+		{"nil", Column{Type: ColumnInt}, NumberFormatPlain, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatValue(&tt.column, tt.format, tt.value); got != tt.want {
+				t.Errorf("formatValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		columnType ColumnType
+		a          interface{}
+		b          interface{}
+		want       int
+	}{
+		{"int", ColumnInt, 9, 10, -1},
+		{"int mixed types", ColumnInt, int64(10), uint16(9), 1},
+		{"float", ColumnFloat, 2.5, 2.5, 0},
+		{"float int", ColumnFloat, 3, 2.5, 1},
+		{"string", ColumnString, "10", "9", -1},
+		{"duration", ColumnDuration, time.Second, time.Minute, -1},
+		{"time", ColumnTime, time.Unix(100, 0), time.Unix(50, 0), 1},
+		{"bool", ColumnBool, false, true, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareValues(tt.columnType, tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_sortRows(t *testing.T) {
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetTypedColumns([]Column{{Name: "Name"}, {Name: "Size", Type: ColumnBytes}})
+	table.AddValues(0, "a", 2048)
+	table.AddValues(1, "b", 100)
+	table.AddValues(2, "c", 1024*1024)
+
+	table.SetSort(2, SortAsc)
+	table.Select(0, 2)
+	table.updateSort()
+
+	want := []string{"c", "a", "b"}
+	for i, name := range want {
+		if got := table.GetCell(i+1, 1).Text; got != name {
+			t.Errorf("sort desc row %d: got %s, want %s", i, got, name)
+		}
+		if got := table.GetCell(i+1, 0).Text; got != []string{"1", "2", "3"}[i] {
+			t.Errorf("sort desc index %d: got %s", i, got)
+		}
+	}
+}
+
+func TestTable_edit(t *testing.T) {
+	key := func(key tcell.Key) *tcell.EventKey { return tcell.NewEventKey(key, 0, tcell.ModNone) }
+	runes := func(text string) []*tcell.EventKey {
+		events := []*tcell.EventKey{}
+		for _, r := range text {
+			events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+		return events
+	}
+	notEmpty := func(value string) error {
+		if value == "" {
+			return fmt.Errorf("empty name")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		column  int
+		keys    []*tcell.EventKey
+		want    string
+		editing bool
+	}{
+		{"bool toggle", 3, []*tcell.EventKey{key(tcell.KeyEnter)}, "false", false},
+		{"enum list", 2, []*tcell.EventKey{key(tcell.KeyEnter), key(tcell.KeyDown), key(tcell.KeyEnter)}, "closed", false},
+		{"integer", 1, append(append([]*tcell.EventKey{key(tcell.KeyEnter)}, runes("x2")...), key(tcell.KeyEnter)), "12", false},
+		{"cancel", 1, append(append([]*tcell.EventKey{key(tcell.KeyEnter)}, runes("3")...), key(tcell.KeyEscape)), "1", false},
+		{"validate error", 0, []*tcell.EventKey{key(tcell.KeyEnter), key(tcell.KeyBackspace2), key(tcell.KeyEnter)}, "a", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewTable()
+			table.SetColumns([]string{"name", "count", "state", "active"})
+			table.AddRow(0, "a", "1", "open", "true")
+			table.SetEditable(true)
+			table.SetColumnEditor(0, &ColumnEditor{Type: EditorText, Validate: notEmpty})
+			table.SetColumnEditor(1, &ColumnEditor{Type: EditorInteger})
+			table.SetColumnEditor(2, &ColumnEditor{Type: EditorEnum, Options: []string{"open", "closed"}})
+			table.SetColumnEditor(3, &ColumnEditor{Type: EditorBool})
+			if _, columns := table.GetSelectable(); !columns {
+				t.Fatalf("cells not selectable after SetEditable")
+			}
+			table.Select(1, tt.column)
+
+			for _, event := range tt.keys {
+				table.InputHandler()(event, nil)
+			}
+			if got := table.GetCell(1, tt.column).Text; got != tt.want {
+				t.Errorf("cell: got %s, want %s", got, tt.want)
+			}
+			if table.IsEditing() != tt.editing {
+				t.Errorf("editing: got %v, want %v", table.IsEditing(), tt.editing)
+			}
+			if tt.editing && table.editor.err == "" {
+				t.Errorf("validation error not shown")
+			}
+		})
+	}
+
+	table := NewTable().SetEditable(true).SetEditable(false)
+	if _, columns := table.GetSelectable(); columns {
+		t.Errorf("cells selectable after disabling editing")
+	}
+}
+
+func TestTable_multiSelect(t *testing.T) {
+	table := NewTable()
+	table.SetColumns([]string{"name"})
+	for i := 0; i < 5; i++ {
+		table.AddRow(i, fmt.Sprintf("row %d", i))
+	}
+	table.SetMultiSelect(true)
+	table.Select(2, 0)
+
+	space := tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)
+	down := tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModShift)
+	up := tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModShift)
+	steps := []struct {
+		name  string
+		event *tcell.EventKey
+		row   int
+		want  []int
+	}{
+		{"toggle on", space, 2, []int{1}},
+		{"toggle off", space, 2, []int{}},
+		{"toggle on again", space, 2, []int{1}},
+		{"grow down", down, 3, []int{1, 2}},
+		{"grow down", down, 4, []int{1, 2, 3}},
+		{"shrink", up, 3, []int{1, 2}},
+		{"shrink to anchor", up, 2, []int{1}},
+		{"grow up", up, 1, []int{0, 1}},
+	}
+	for _, step := range steps {
+		table.InputHandler()(step.event, nil)
+		row, _ := table.GetSelection()
+		if got := table.GetSelectedRows(); row != step.row || !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: got row %d selected %v, want row %d selected %v", step.name, row, got, step.row, step.want)
+		}
+	}
+
+	table.SetSelectedRows([]int{4, 0})
+	if got := table.GetSelectedRows(); !reflect.DeepEqual(got, []int{0, 4}) {
+		t.Errorf("SetSelectedRows: got %v", got)
+	}
+	table.ClearSelectedRows()
+	if got := table.GetSelectedRows(); len(got) != 0 {
+		t.Errorf("ClearSelectedRows: got %v", got)
+	}
+}
+
+func TestTable_mouse(t *testing.T) {
+	screen := newTestScreen(t)
+	table := NewTable()
+	table.SetColumns([]string{"name", "type"})
+	table.AddRow(0, "alpha", "a")
+	table.AddRow(1, "beta", "b")
+	table.AddRow(2, "gamma", "b")
+	table.SetRect(0, 0, 40, 10)
+
+	sorted := ""
+	table.SetSortFunc(func(column string, sort Sort) {
+		sorted = column
+	})
+	selected := -1
+	table.SetSelectedFunc(func(row, column int) {
+		selected = row
+	})
+	click := func(action cview.MouseAction, row, column int) {
+		table.Draw(screen)
+		x, y, _ := table.GetCell(row, column).GetLastPosition()
+		table.MouseHandler()(action, tcell.NewEventMouse(x, y, tcell.Button1, tcell.ModNone), func(p cview.Primitive) {})
+	}
+
+	table.Select(1, 0)
+	click(cview.MouseLeftClick, 0, 1)
+	if row, _ := table.GetSelection(); sorted != "type" || row != 1 {
+		t.Errorf("header click: sorted by %q, selected row %d", sorted, row)
+	}
+	click(cview.MouseLeftClick, 2, 0)
+	if row, _ := table.GetSelection(); row != 2 || selected != -1 {
+		t.Errorf("row click: selected row %d, activated %d", row, selected)
+	}
+	click(cview.MouseLeftDoubleClick, 3, 0)
+	if row, _ := table.GetSelection(); row != 3 || selected != 3 {
+		t.Errorf("double-click: selected row %d, activated %d", row, selected)
+	}
+
+	table.SetGroupBy(1)
+	table.Draw(screen)
+	click(cview.MouseLeftClick, 1, 0)
+	click(cview.MouseLeftDoubleClick, 1, 0)
+	if group := table.groupAt(1); group == nil || !group.collapsed {
+		t.Errorf("double-click toggled group back")
+	}
+}
+
+func TestTable_frozenColumns(t *testing.T) {
+	screen := newTestScreen(t)
+	table := NewTable()
+	columns := []string{"name"}
+	values := []string{"first"}
+	for i := 1; i < 10; i++ {
+		columns = append(columns, fmt.Sprintf("column %d", i))
+		values = append(values, fmt.Sprintf("value %d", i))
+	}
+	table.SetColumns(columns)
+	table.AddRow(0, values...)
+	table.SetFrozenColumns(1)
+	table.SetRect(0, 0, 40, 10)
+
+	right := tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone)
+	tests := []struct {
+		name   string
+		scroll int
+		left   bool
+		right  bool
+		column string
+	}{
+		{"start", 0, false, true, "column 1"},
+		{"middle", 2, true, true, "column 3"},
+		{"end", 10, true, false, "column 9"},
+	}
+	for _, tt := range tests {
+		for i := 0; i < tt.scroll; i++ {
+			table.InputHandler()(right, nil)
+		}
+		table.Draw(screen)
+		screen.Show()
+		header := string([]rune(screenRow(screen, 0))[:40])
+		row := string([]rune(screenRow(screen, 1))[:40])
+
+		if !strings.HasPrefix(header, "name") || !strings.HasPrefix(row, "first") {
+			t.Errorf("%s: frozen column moved: %q, %q", tt.name, header, row)
+		}
+		if !strings.Contains(header, tt.column) {
+			t.Errorf("%s: column %s not visible: %q", tt.name, tt.column, header)
+		}
+		if got := strings.ContainsRune(header, arrowLeftSmall); got != tt.left {
+			t.Errorf("%s: left indicator: got %v, want %v", tt.name, got, tt.left)
+		}
+		if got := []rune(header)[39] == arrowRightSmall; got != tt.right {
+			t.Errorf("%s: right indicator: got %v, want %v: %q", tt.name, got, tt.right, header)
+		}
+	}
+}
+
+func TestTable_footer(t *testing.T) {
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetTypedColumns([]Column{{Name: "Name"}, {Name: "Size", Type: ColumnInt},
+		{Name: "Duration", Type: ColumnDuration}})
+	table.SetNumberFormat(NumberFormatEnglish)
+	table.SetFooterLabel("Total")
+	table.SetColumnAggregation(1, AggregateCount)
+	table.SetColumnAggregation(2, AggregateSum)
+	table.SetColumnAggregation(3, AggregateMax)
+
+	table.AddValues(0, "a", 1500, time.Second)
+	table.AddValues(1, "b", 500, time.Minute)
+	table.AddValues(2, "c", 1000, time.Millisecond)
+
+	tests := []struct {
+		column int
+		want   string
+	}{
+		{0, "Total"},
+		{1, "3"},
+		{2, "3,000"},
+		{3, "1m0s"},
+	}
+	for _, tt := range tests {
+		if got := table.GetFooterCell(tt.column).Text; got != tt.want {
+			t.Errorf("footer column %d: got %s, want %s", tt.column, got, tt.want)
+		}
+	}
+
+	table.RemoveRow(0)
+	table.SetColumnAggregation(2, AggregateAverage)
+	if got := table.GetFooterCell(2).Text; got != "750.00" {
+		t.Errorf("footer average after remove: got %s, want 750.00", got)
+	}
+
+	screen := newTestScreen(t)
+	table.SetRect(0, 0, 40, 10)
+	table.SetBorderPadding(1, 1, 0, 0)
+	for i := 0; i < 2; i++ {
+		table.Draw(screen)
+		screen.Show()
+		if got := screenRow(screen, 8); !strings.Contains(got, "1m0s") {
+			t.Errorf("draw %d: footer not above bottom padding: %q", i, got)
+		}
+		if got := strings.TrimSpace(screenRow(screen, 0)); got != "" {
+			t.Errorf("draw %d: top padding not kept: %q", i, got)
+		}
+	}
+}
+
+func TestTable_groups(t *testing.T) {
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetTypedColumns([]Column{{Name: "Name"}, {Name: "Type"}, {Name: "Size", Type: ColumnInt}})
+	table.AddValues(0, "a", "file", 10)
+	table.AddValues(1, "b", "dir", 20)
+	table.AddValues(2, "c", "file", 30)
+	table.SetColumnAggregation(3, AggregateSum)
+	table.SetGroupSubtotals(true)
+	headerRows := map[int]bool{}
+	table.SetAddCellFunc(func(cell *cview.TableCell, header bool, row int) {
+		if header {
+			headerRows[row] = true
+		}
+	})
+	table.SetGroupBy(2)
+	if !reflect.DeepEqual(headerRows, map[int]bool{1: true, 3: true}) {
+		t.Errorf("group header rows passed to add cell func: %v", headerRows)
+	}
+
+	texts := func() []string {
+		out := []string{}
+		for row := 1; row < table.GetRowCount(); row++ {
+			out = append(out, table.GetCell(row, 0).Text+table.GetCell(row, 1).Text+table.GetCell(row, 3).Text)
+		}
+		return out
+	}
+
+	want := []string{"▼ dir (1)20", "1b20", "▼ file (2)40", "2a10", "3c30"}
+	if got := texts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("grouped rows: got %v, want %v", got, want)
+	}
+
+	table.SetGroupIndexRestart(true)
+	table.toggleGroup(1)
+	want = []string{"▶ dir (1)20", "▼ file (2)40", "1a10", "2c30"}
+	if got := texts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("collapsed group: got %v, want %v", got, want)
+	}
+
+	if got := table.GetFooterCell(3).Text; got != "60" {
+		t.Errorf("footer with collapsed group: got %s, want 60", got)
+	}
+
+	table.SetSort(3, SortAsc)
+	table.Select(0, 3)
+	table.updateSort()
+	want = []string{"▶ dir (1)20", "▼ file (2)40", "1c30", "2a10"}
+	if got := texts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted groups: got %v, want %v", got, want)
+	}
+
+	table.SetGroupBy(-1)
+	want = []string{"1c30", "2b20", "3a10"}
+	if got := texts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ungrouped: got %v, want %v", got, want)
+	}
+}
+
+func TestTable_groupIndices(t *testing.T) {
+	table := NewTable()
+	table.SetColumns([]string{"name", "type"})
+	table.AddRow(0, "a", "file")
+	table.AddRow(1, "b", "dir")
+	table.AddRow(2, "c", "file")
+	table.SetGroupBy(1)
+	// ▼ dir, b, ▼ file, a, c
+	table.SetMultiSelect(true)
+	var copied string
+	table.SetCopyFunc(func(text string, osc52 bool) {
+		copied = text
+	})
+	edited := -1
+	table.SetEditFunc(func(index, column int, value string) error {
+		edited = index
+		return nil
+	})
+	table.SetEditable(true)
+	table.SetColumnEditor(0, &ColumnEditor{Type: EditorText})
+
+	table.Select(4, 0)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), nil)
+	if got := table.GetSelectedRows(); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("selected rows: got %v, want [1]", got)
+	}
+	table.SetSelectedRows([]int{0, 2})
+	if !table.isRowSelected(2) || !table.isRowSelected(5) || table.isRowSelected(4) {
+		t.Errorf("SetSelectedRows selected wrong rows")
+	}
+	if got := table.GetValue(2, 0); got != "c" {
+		t.Errorf("GetValue(2): got %v, want c", got)
+	}
+
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	if edited != 1 {
+		t.Errorf("edit index: got %d, want 1", edited)
+	}
+
+	// Rows in collapsed group keep their indices
+	table.toggleGroup(1)
+	if got := table.GetSelectedRows(); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Errorf("selected rows with collapsed group: got %v", got)
+	}
+	if row := table.indexRow(0); row != -1 {
+		t.Errorf("row of collapsed index: got %d", row)
+	}
+	if row := table.indexRow(2); row != 4 || table.rowIndex(row) != 2 {
+		t.Errorf("row of index 2: got %d", row)
+	}
+	table.CopyCell(0, 0)
+	table.Draw(newTestScreen(t))
+	if copied != "b" {
+		t.Errorf("copy cell in collapsed group: got %q", copied)
+	}
+}
+
+func TestTable_styleRules(t *testing.T) {
+	table := NewTable()
+	table.SetTypedColumns([]Column{{Name: "Name"}, {Name: "Load", Type: ColumnFloat}})
+	table.AddValues(0, "a", 0.2)
+	table.AddValues(1, "b", 0.95)
+	table.AddValues(2, "c", 0.6)
+
+	table.AddStyleRule(RuleThresholds(1, []Threshold{
+		{Value: 0.5, Style: tcell.StyleDefault.Foreground(tcell.ColorYellow)},
+		{Value: 0.9, Style: tcell.StyleDefault.Foreground(tcell.ColorRed)},
+	}))
+	table.AddStyleRule(RuleMax(1, tcell.StyleDefault.Bold(true)))
+	table.updateStyles()
+
+	tests := []struct {
+		row   int
+		color tcell.Color
+		bold  bool
+	}{
+		{1, cview.Styles.PrimaryTextColor, false},
+		{2, tcell.ColorRed, true},
+		{3, tcell.ColorYellow, false},
+	}
+	for _, tt := range tests {
+		cell := table.GetCell(tt.row, 1)
+		if cell.Color != tt.color {
+			t.Errorf("row %d color: got %v, want %v", tt.row, cell.Color, tt.color)
+		}
+		if (cell.Attributes&tcell.AttrBold != 0) != tt.bold {
+			t.Errorf("row %d bold: got %v, want %v", tt.row, cell.Attributes, tt.bold)
+		}
+	}
+
+	table.AddValues(3, "d", 1.5)
+	table.updateStyles()
+	if table.GetCell(2, 1).Attributes&tcell.AttrBold != 0 {
+		t.Errorf("previous max still highlighted after adding larger value")
+	}
+	table.ClearStyleRules()
+	table.updateStyles()
+	if table.GetCell(2, 1).Color != cview.Styles.PrimaryTextColor {
+		t.Errorf("style not restored after clearing rules")
+	}
+}
+
+func TestTable_UpdateRows(t *testing.T) {
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetColumns([]string{"name", "value"})
+	table.UpdateRows([]string{"a", "b", "c"}, [][]string{{"a", "1"}, {"b", "2"}, {"c", "3"}})
+	table.Select(2, 1)
+
+	cell := table.GetCell(2, 2)
+	table.UpdateRows([]string{"d", "a", "b", "c"}, [][]string{{"d", "4"}, {"a", "1"}, {"b", "5"}, {"c", "1"}})
+
+	if row, _ := table.GetSelection(); row != 3 || table.GetRowKey(row-1) != "b" {
+		t.Errorf("selected row not kept: got row %d with key %s", row, table.GetRowKey(row-1))
+	}
+	if table.GetCell(3, 2) != cell {
+		t.Errorf("existing cell was replaced instead of patched")
+	}
+	if table.GetCell(3, 2).Text != "5" || table.GetCell(3, 0).Text != "3" {
+		t.Errorf("row not updated: got %s, index %s", table.GetCell(3, 2).Text, table.GetCell(3, 0).Text)
+	}
+
+	tests := []struct {
+		row   int
+		color tcell.Color
+		flash bool
+	}{
+		{1, 0, false},
+		{2, 0, false},
+		{3, tcell.ColorDarkGreen, true},
+		{4, tcell.ColorDarkRed, true},
+	}
+	for _, tt := range tests {
+		flash, ok := table.flashes[table.GetCell(tt.row, 2)]
+		if ok != tt.flash || flash.color != tt.color {
+			t.Errorf("row %d flash: got %v %v, want %v %v", tt.row, ok, flash.color, tt.flash, tt.color)
+		}
+	}
+
+	table.UpdateRows([]string{"c"}, [][]string{{"c", "1"}})
+	if table.GetRowCount() != 2 || len(table.rowKeys) != 1 {
+		t.Errorf("removed rows not cleared: %d rows, %d keys", table.GetRowCount(), len(table.rowKeys))
+	}
+	if row, _ := table.GetSelection(); row != 1 {
+		t.Errorf("selection not moved to remaining row: got %d", row)
+	}
+
+	if err := table.UpdateRows([]string{"c", "d"}, [][]string{{"c", "1"}}); err == nil {
+		t.Errorf("no error with more keys than rows")
+	}
+	if err := table.UpdateValues([]string{"c"}, [][]interface{}{{"c", 1}, {"d", 2}}); err == nil {
+		t.Errorf("no error with more rows than keys")
+	}
+	if table.GetRowCount() != 2 {
+		t.Errorf("table modified after error: %d rows", table.GetRowCount())
+	}
+}
+
+func TestTable_follow(t *testing.T) {
+	screen := newTestScreen(t)
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetColumns([]string{"message"})
+	table.SetRect(0, 0, 40, 10)
+	table.SetFollow(true)
+	table.SetRowLimit(3)
+
+	for i := 0; i < 5; i++ {
+		table.AppendRow(fmt.Sprintf("message %d", i))
+		table.Draw(screen)
+	}
+	if row, _ := table.GetSelection(); row != 3 || !table.IsFollowing() {
+		t.Errorf("last row not followed: selected %d, following %v", row, table.IsFollowing())
+	}
+	if got := table.GetCell(1, 0).Text; got != "3" {
+		t.Errorf("index after dropping rows: got %s, want 3", got)
+	}
+
+	table.Select(2, 0)
+	table.Draw(screen)
+	table.AppendRow("message 5")
+	table.AppendRow("message 6")
+	table.Draw(screen)
+	if table.IsFollowing() || table.newRows != 2 {
+		t.Errorf("following not paused: following %v, new rows %d", table.IsFollowing(), table.newRows)
+	}
+	if row, _ := table.GetSelection(); row != 1 || table.GetCell(1, 1).Text != "message 4" {
+		t.Errorf("selected row moved: row %d, %s", row, table.GetCell(row, 1).Text)
+	}
+
+	table.Select(3, 0)
+	table.Draw(screen)
+	if !table.IsFollowing() || table.newRows != 0 {
+		t.Errorf("following not resumed")
+	}
+}
+
+func TestTable_rowLimitAddRow(t *testing.T) {
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetColumns([]string{"message"})
+	table.SetRowLimit(3)
+	for i := 0; i < 6; i++ {
+		table.AddRow(i, fmt.Sprintf("message %d", i))
+	}
+
+	if table.GetRowCount() != 4 {
+		t.Fatalf("row count: got %d, want 4", table.GetRowCount())
+	}
+	for row := 1; row < table.GetRowCount(); row++ {
+		number, text := table.GetCell(row, 0).Text, table.GetCell(row, 1).Text
+		if number != fmt.Sprint(row+3) || text != fmt.Sprintf("message %d", row+2) {
+			t.Errorf("row %d: got %s %s", row, number, text)
+		}
+	}
+}
+
+func Test_highlightText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		query    string
+		maxWidth int
+		want     string
+	}{
+		{"no match", "abc", "x", 0, "abc"},
+		{"case insensitive", "Foo bar foo", "foo", 0, "[#000000:#ffff00]Foo[-:-] bar [#000000:#ffff00]foo[-:-]"},
+		{"fits", "abcdef", "cd", 5, "ab[#000000:#ffff00]cd[-:-]ef"},
+		{"truncated", "abcdefghij", "hi", 5, "…g[#000000:#ffff00]hi[-:-]j"},
+		{"truncated at end", "abcdefghij", "ij", 4, "…h[#000000:#ffff00]ij[-:-]"},
+		{"color tag", "[red]red[-] ed", "ed", 0, "[red]r[#000000:#ffff00]ed[-:-][-] [#000000:#ffff00]ed[-:-]"},
+		{"tag only", "[red]abc", "red", 0, "[red]abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := highlightText(tt.text, tt.query, tt.maxWidth, tcell.ColorBlack, tcell.ColorYellow)
+			if got != tt.want {
+				t.Errorf("highlightText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_search(t *testing.T) {
+	table := NewTable()
+	table.SetColumns([]string{"name", "description"})
+	table.AddRow(0, "alpha", "first")
+	table.AddRow(1, "beta", "alpha and beta")
+	table.AddRow(2, "gamma", "none")
+	table.AddRow(3, "delta", "Alpha")
+	table.Select(1, 0)
+
+	slash := tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone)
+	table.InputHandler()(slash, nil)
+	if table.search != nil && table.search.input != nil {
+		t.Errorf("search prompt opened while search is disabled")
+	}
+	table.SetSearchEnabled(true)
+	for _, r := range "/alpha" {
+		table.InputHandler()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), nil)
+	}
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	if table.GetSearchQuery() != "alpha" || len(table.search.matches) != 3 {
+		t.Fatalf("search: query %s, %d matches", table.GetSearchQuery(), len(table.search.matches))
+	}
+
+	next := tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone)
+	prev := tcell.NewEventKey(tcell.KeyRune, 'N', tcell.ModNone)
+	steps := []struct {
+		event *tcell.EventKey
+		row   int
+		col   int
+	}{
+		{next, 2, 1},
+		{next, 4, 1},
+		{next, 1, 0},
+		{prev, 4, 1},
+	}
+	if row, _ := table.GetSelection(); row != 1 || table.search.current != 0 {
+		t.Errorf("enter did not select first match: row %d", row)
+	}
+	for i, step := range steps {
+		table.InputHandler()(step.event, nil)
+		row, _ := table.GetSelection()
+		match := table.search.matches[table.search.current]
+		if row != step.row || match.col != step.col {
+			t.Errorf("step %d: got row %d col %d, want row %d col %d", i, row, match.col, step.row, step.col)
+		}
+	}
+	table.SetRect(0, 0, 40, 10)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone), nil)
+	table.Draw(newTestScreen(t))
+	if table.GetCell(4, 1).Text != "Alpha" {
+		t.Errorf("cell text not restored after drawing: %s", table.GetCell(4, 1).Text)
+	}
+
+	table.AddRow(4, "[red]epsilon[-]", "")
+	table.SetSearchQuery("red")
+	table.updateMatches()
+	if len(table.search.matches) != 0 {
+		t.Errorf("query matched inside color tag: %v", table.search.matches)
+	}
+}
+
+func TestTable_tree(t *testing.T) {
+	table := NewTable()
+	table.SetTypedColumns([]Column{{Name: "name"}, {Name: "size", Type: ColumnInt}})
+
+	docs := NewTreeNodeValues("docs", 10)
+	docs.AddChild(NewTreeNodeValues("b.txt", 3))
+	docs.AddChild(NewTreeNodeValues("a.txt", 7))
+	src := NewTreeNodeValues("src", 20).SetExpandable(true)
+	loads := 0
+	table.SetTreeLoadFunc(func(node *TreeNode) []*TreeNode {
+		loads++
+		return []*TreeNode{NewTreeNodeValues("main.go", 5), NewTreeNodeValues("util.go", 15)}
+	})
+	table.SetTreeRoots([]*TreeNode{docs, src})
+
+	rows := func() []string {
+		texts := []string{}
+		for row := 1; row < table.GetRowCount(); row++ {
+			texts = append(texts, table.GetCell(row, 0).Text)
+		}
+		return texts
+	}
+
+	if got := rows(); !reflect.DeepEqual(got, []string{"▶ docs", "▶ src"}) {
+		t.Errorf("collapsed tree: got %v", got)
+	}
+
+	table.Select(2, 0)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone), nil)
+	table.SetTreeExpanded(docs, true)
+	table.SetTreeExpanded(src, false)
+	table.SetTreeExpanded(src, true)
+	if loads != 1 {
+		t.Errorf("children loaded %d times, want 1", loads)
+	}
+
+	table.sortCol = 1
+	table.sortType = SortDesc
+	table.sortTree()
+	want := []string{"▼ src", "    util.go", "    main.go", "▼ docs", "    a.txt", "    b.txt"}
+	if got := rows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted tree: got %v, want %v", got, want)
+	}
+	if node := table.GetSelectedNode(); node != src {
+		t.Errorf("selected node not kept after sort")
+	}
+
+	table.Select(5, 0)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone), nil)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone), nil)
+	if got := rows(); !reflect.DeepEqual(got, []string{"▼ src", "    util.go", "    main.go", "▶ docs"}) {
+		t.Errorf("collapse from child: got %v", got)
+	}
+	if node := table.GetSelectedNode(); node != docs {
+		t.Errorf("collapsed parent not selected")
+	}
+
+	if table.treeInput(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone)) {
+		t.Errorf("left on collapsed root was handled")
+	}
+	table.Select(2, 0)
+	if table.treeInput(tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone)) {
+		t.Errorf("right on leaf was handled")
+	}
+}
+
+// rawScreen records escape sequences written to screen
+type rawScreen struct {
+	tcell.SimulationScreen
+	written string
+}
+
+func (r *rawScreen) TPuts(s string) {
+	r.written += s
+}
+
+func TestTable_copy(t *testing.T) {
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetColumns([]string{"name", "value"})
+	table.AddRow(0, "a", "1")
+	table.AddRow(1, "b", "2")
+	table.AddRow(2, "c", "3")
+	table.SetRect(0, 0, 40, 10)
+
+	var copied string
+	var sent bool
+	table.SetCopyFunc(func(text string, osc52 bool) {
+		copied = text
+		sent = osc52
+	})
+
+	screen := &rawScreen{SimulationScreen: newTestScreen(t)}
+	table.Select(2, 0)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone), nil)
+	table.Draw(screen)
+	if copied != "b\t2" || !sent || screen.written != "\x1b]52;c;Ygky\a" {
+		t.Errorf("copy row: got %q, osc52 %v, written %q", copied, sent, screen.written)
+	}
+
+	table.SetMultiSelect(true)
+	table.SetSelectedRows([]int{0, 2})
+	table.CopySelectedRows()
+	table.Draw(newTestScreen(t))
+	if copied != "a\t1\nc\t3" || sent {
+		t.Errorf("copy selected rows: got %q, osc52 %v", copied, sent)
+	}
+
+	table.CopyCell(1, 2)
+	table.Draw(newTestScreen(t))
+	if copied != "2" {
+		t.Errorf("copy cell: got %q", copied)
+	}
+
+	cellKey := tcell.NewEventKey(tcell.KeyRune, 'Y', tcell.ModNone)
+	table.Select(3, 1)
+	table.InputHandler()(cellKey, nil)
+	table.Draw(newTestScreen(t))
+	if copied != "2" {
+		t.Errorf("copied cell while columns are not selectable: got %q", copied)
+	}
+	table.SetEditable(true)
+	table.InputHandler()(cellKey, nil)
+	table.Draw(newTestScreen(t))
+	if copied != "c" {
+		t.Errorf("copy selected cell: got %q", copied)
+	}
+}
+
+func TestTable_pagination(t *testing.T) {
+	screen := newTestScreen(t)
+	table := NewTable()
+	table.SetShowIndex(true)
+	table.SetColumns([]string{"name"})
+	table.SetRect(0, 0, 40, 10)
+
+	var requests []int
+	var pending []func(Page)
+	fetch := func(page, pageSize int, done func(Page)) {
+		requests = append(requests, page)
+		pending = append(pending, func(p Page) { done(p) })
+	}
+	respond := func(i, page, pageSize int) {
+		rows := [][]string{}
+		for row := page * pageSize; row < min((page+1)*pageSize, 7); row++ {
+			rows = append(rows, []string{fmt.Sprintf("row %d", row)})
+		}
+		pending[i](Page{Rows: rows, Total: 7})
+	}
+
+	table.SetPageFetchFunc(fetch, 3)
+	if !table.IsLoading() {
+		t.Errorf("table not loading")
+	}
+	respond(0, 0, 3)
+	table.Draw(screen)
+	if table.IsLoading() || table.GetPageCount() != 3 || table.GetRowCount() != 4 {
+		t.Errorf("first page: loading %v, pages %d, rows %d", table.IsLoading(), table.GetPageCount(), table.GetRowCount())
+	}
+
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone), nil)
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyRune, ']', tcell.ModNone), nil)
+	if !reflect.DeepEqual(requests, []int{0, 1, 2}) {
+		t.Errorf("requested pages: %v", requests)
+	}
+	// Response to older request is ignored
+	respond(2, 2, 3)
+	respond(1, 1, 3)
+	table.Draw(screen)
+	if table.GetPage() != 2 || table.GetRowCount() != 2 {
+		t.Errorf("last page: page %d, rows %d", table.GetPage(), table.GetRowCount())
+	}
+	if got := table.GetCell(1, 0).Text; got != "7" {
+		t.Errorf("global index: got %s, want 7", got)
+	}
+
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone), nil)
+	if len(requests) != 3 {
+		t.Errorf("requested page after last page")
+	}
+
+	// Failed fetch keeps current page
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyPgUp, 0, tcell.ModNone), nil)
+	pending[3](Page{Err: fmt.Errorf("timeout")})
+	table.Draw(screen)
+	if table.GetPage() != 2 || table.GetRowCount() != 2 {
+		t.Errorf("failed page: page %d, rows %d", table.GetPage(), table.GetRowCount())
+	}
+	table.ReloadPage()
+	if requests[len(requests)-1] != 2 {
+		t.Errorf("reload after failure: requested %v", requests)
+	}
+	respond(4, 2, 3)
+
+	// Footer and page indicator have separate rows
+	table.SetColumnAggregation(1, AggregateCount)
+	table.Draw(screen)
+	screen.Show()
+	if footer := screenRow(screen, 8); strings.Contains(footer, "Page") || !strings.Contains(footer, "1") {
+		t.Errorf("footer row: %q", footer)
+	}
+	if pager := screenRow(screen, 9); !strings.Contains(pager, "Page 3/3") {
+		t.Errorf("page indicator row: %q", pager)
+	}
+}
@@ -0,0 +1,389 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"sort"
+	"strings"
+)
+
+// TreeNode is a single row in tree table. Node can have children, that are shown below node when it
+// is expanded.
+type TreeNode struct {
+	texts      []string
+	values     []interface{}
+	reference  interface{}
+	parent     *TreeNode
+	children   []*TreeNode
+	expandable bool
+	loaded     bool
+	expanded   bool
+	cells      []*cview.TableCell
+}
+
+// NewTreeNode creates new tree node with cell texts. Index column must not be included.
+func NewTreeNode(texts ...string) *TreeNode {
+	return &TreeNode{texts: texts}
+}
+
+// NewTreeNodeValues creates new tree node with raw values, that are formatted according to column types
+// set with SetTypedColumns. Index column must not be included.
+func NewTreeNodeValues(values ...interface{}) *TreeNode {
+	return &TreeNode{values: values}
+}
+
+// AddChild adds child node.
+func (n *TreeNode) AddChild(child *TreeNode) *TreeNode {
+	child.parent = n
+	n.children = append(n.children, child)
+	n.loaded = true
+	return n
+}
+
+// SetExpandable marks node as having children, which are loaded with tree load func when node is
+// expanded for first time. See Table.SetTreeLoadFunc.
+func (n *TreeNode) SetExpandable(expandable bool) *TreeNode {
+	n.expandable = expandable
+	return n
+}
+
+// SetReference sets user data for node.
+func (n *TreeNode) SetReference(reference interface{}) *TreeNode {
+	n.reference = reference
+	return n
+}
+
+// GetReference returns user data of node.
+func (n *TreeNode) GetReference() interface{} {
+	return n.reference
+}
+
+// GetChildren returns children of node.
+func (n *TreeNode) GetChildren() []*TreeNode {
+	return n.children
+}
+
+// GetParent returns parent of node, or nil if node is a root node.
+func (n *TreeNode) GetParent() *TreeNode {
+	return n.parent
+}
+
+// IsExpanded returns true if node's children are shown.
+func (n *TreeNode) IsExpanded() bool {
+	return n.expanded
+}
+
+// hasChildren returns true if node has or might have children
+func (n *TreeNode) hasChildren() bool {
+	return len(n.children) > 0 || n.expandable && !n.loaded
+}
+
+// depth returns number of ancestors
+func (n *TreeNode) depth() int {
+	depth := 0
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+	return depth
+}
+
+// tableTree is state of tree mode in table
+type tableTree struct {
+	roots    []*TreeNode
+	loadFunc func(node *TreeNode) []*TreeNode
+	// nodes maps first cell of each visible row to its node
+	nodes  map[*cview.TableCell]*TreeNode
+	sorted bool
+}
+
+// SetTreeRoots enables tree mode and sets root nodes. First data column shows indentation and
+// markers for expanding and collapsing nodes. Node is expanded and collapsed with Enter, right and left
+// arrows or mouse click on marker. Sorting sorts each group of siblings separately. Tree mode
+// replaces rows added with AddRow, and it cannot be used with grouping. Nil roots disables tree mode.
+func (t *Table) SetTreeRoots(roots []*TreeNode) *Table {
+	if roots == nil {
+		t.tree = nil
+		t.setDataRows(nil)
+		t.dataChanged()
+		return t
+	}
+	if t.tree == nil {
+		t.tree = &tableTree{}
+	}
+	for _, root := range roots {
+		root.parent = nil
+	}
+	t.tree.roots = roots
+	if t.tree.sorted {
+		t.sortNodes(roots, true)
+	}
+	t.rebuildTree(nil)
+	return t
+}
+
+// SetTreeLoadFunc sets function that loads children of expandable node when it is expanded for the first
+// time.
+func (t *Table) SetTreeLoadFunc(load func(node *TreeNode) []*TreeNode) *Table {
+	if t.tree == nil {
+		t.tree = &tableTree{}
+	}
+	t.tree.loadFunc = load
+	return t
+}
+
+// SetTreeExpanded expands or collapses node.
+func (t *Table) SetTreeExpanded(node *TreeNode, expanded bool) *Table {
+	if t.tree == nil || node.expanded == expanded {
+		return t
+	}
+	selected := t.GetSelectedNode()
+	if expanded && !node.loaded {
+		node.loaded = true
+		if t.tree.loadFunc != nil {
+			for _, child := range t.tree.loadFunc(node) {
+				node.AddChild(child)
+			}
+			if t.tree.sorted {
+				t.sortNodes(node.children, true)
+			}
+		}
+	}
+	node.expanded = expanded
+	if !expanded && selected != nil {
+		// Move selection out of collapsed subtree
+		for p := selected.parent; p != nil; p = p.parent {
+			if p == node {
+				selected = node
+				break
+			}
+		}
+	}
+	t.rebuildTree(selected)
+	return t
+}
+
+// GetSelectedNode returns node in selected row, or nil if tree mode is disabled or no row is selected.
+func (t *Table) GetSelectedNode() *TreeNode {
+	if t.tree == nil {
+		return nil
+	}
+	row, _ := t.Table.GetSelection()
+	if row < 1 {
+		return nil
+	}
+	return t.tree.nodes[t.Table.GetCell(row, 0)]
+}
+
+// treeColumn returns column that shows tree structure
+func (t *Table) treeColumn() int {
+	if t.showIndex {
+		return 1
+	}
+	return 0
+}
+
+// nodeCells returns cells for node, creating them if needed
+func (t *Table) nodeCells(node *TreeNode, index int) []*cview.TableCell {
+	if node.cells != nil {
+		return node.cells
+	}
+	texts := node.texts
+	if node.values != nil {
+		offset := t.treeColumn()
+		texts = make([]string, len(node.values))
+		for i, v := range node.values {
+			texts[i] = t.formatValue(i+offset, v)
+		}
+	}
+	node.cells = t.newRowCells(index, texts, node.values)
+	if node.values == nil && len(texts) > 0 {
+		// Keep plain text for sorting and search, cell text contains tree prefix
+		node.cells[t.treeColumn()].SetReference(texts[0])
+	}
+	return node.cells
+}
+
+// rebuildTree sets visible nodes as table rows. Selected node is kept selected if visible.
+func (t *Table) rebuildTree(selected *TreeNode) {
+	if selected == nil {
+		selected = t.GetSelectedNode()
+	}
+	rows := [][]*cview.TableCell{}
+	nodes := map[*cview.TableCell]*TreeNode{}
+	treeCol := t.treeColumn()
+	selectedRow := -1
+
+	var walk func(list []*TreeNode)
+	walk = func(list []*TreeNode) {
+		for _, node := range list {
+			cells := t.nodeCells(node, len(rows))
+			if node == selected {
+				selectedRow = len(rows) + 1
+			}
+			if treeCol < len(cells) {
+				cells[treeCol].SetAlign(cview.AlignLeft)
+				cells[treeCol].SetText(treePrefix(node) + t.nodeText(node))
+			}
+			nodes[cells[0]] = node
+			rows = append(rows, cells)
+			if node.expanded {
+				walk(node.children)
+			}
+		}
+	}
+	walk(t.tree.roots)
+
+	t.tree.nodes = nodes
+	t.setDataRows(rows)
+	t.renumber()
+	t.dataChanged()
+	if selectedRow > 0 {
+		_, col := t.Table.GetSelection()
+		t.Table.Select(selectedRow, col)
+	}
+}
+
+// nodeText returns text of tree column without tree prefix
+func (t *Table) nodeText(node *TreeNode) string {
+	if node.values != nil {
+		if len(node.values) == 0 {
+			return ""
+		}
+		return t.formatValue(t.treeColumn(), node.values[0])
+	}
+	if len(node.texts) == 0 {
+		return ""
+	}
+	return node.texts[0]
+}
+
+// treePrefix returns indentation and marker for node
+func treePrefix(node *TreeNode) string {
+	marker := "  "
+	if node.hasChildren() {
+		if node.expanded {
+			marker = arrowDown + " "
+		} else {
+			marker = arrowRight + " "
+		}
+	}
+	return strings.Repeat("  ", node.depth()) + marker
+}
+
+// sortTree sorts each sibling group by current sort column
+func (t *Table) sortTree() {
+	t.tree.sorted = true
+	t.sortNodes(t.tree.roots, true)
+	t.rebuildTree(nil)
+}
+
+// sortNodes sorts nodes by current sort column. If recursive, all descendants are sorted too.
+func (t *Table) sortNodes(nodes []*TreeNode, recursive bool) {
+	columnType := ColumnString
+	if column := t.column(t.sortCol); column != nil {
+		columnType = column.Type
+	}
+	for i, node := range nodes {
+		t.nodeCells(node, i)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i].cells, nodes[j].cells
+		if t.sortCol >= len(a) || t.sortCol >= len(b) {
+			return false
+		}
+		c := compareCells(columnType, a[t.sortCol], b[t.sortCol])
+		if t.sortType == SortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+	if recursive {
+		for _, node := range nodes {
+			t.sortNodes(node.children, true)
+		}
+	}
+}
+
+// treeInput handles keys for expanding and collapsing nodes. Return true if event was handled.
+func (t *Table) treeInput(event *tcell.EventKey) bool {
+	node := t.GetSelectedNode()
+	if node == nil {
+		return false
+	}
+	key := event.Key()
+	if key == tcell.KeyRune {
+		switch event.Rune() {
+		case '+':
+			key = tcell.KeyRight
+		case '-':
+			key = tcell.KeyLeft
+		}
+	}
+
+	switch key {
+	case tcell.KeyEnter:
+		if !node.hasChildren() {
+			return false
+		}
+		t.SetTreeExpanded(node, !node.expanded)
+	case tcell.KeyRight:
+		if !node.hasChildren() {
+			return false
+		}
+		t.SetTreeExpanded(node, true)
+	case tcell.KeyLeft:
+		if node.expanded {
+			t.SetTreeExpanded(node, false)
+		} else if node.parent != nil {
+			t.rebuildTree(node.parent)
+		} else {
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// treeClick toggles node if click at x was on its marker. Return true if node was toggled.
+func (t *Table) treeClick(row, x int) bool {
+	node := t.treeMarkerAt(row, x)
+	if node == nil {
+		return false
+	}
+	t.SetTreeExpanded(node, !node.expanded)
+	return true
+}
+
+// treeMarkerAt returns node if x is on expand marker of node at row.
+func (t *Table) treeMarkerAt(row, x int) *TreeNode {
+	if t.tree == nil || row < 1 {
+		return nil
+	}
+	node := t.tree.nodes[t.Table.GetCell(row, 0)]
+	if node == nil || !node.hasChildren() {
+		return nil
+	}
+	cellX, _, _ := t.Table.GetCell(row, t.treeColumn()).GetLastPosition()
+	markerX := cellX + node.depth()*2
+	if x < markerX || x > markerX+1 {
+		return nil
+	}
+	return node
+}
@@ -0,0 +1,486 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strconv"
+)
+
+// TableDataProvider provides rows for VirtualTable. Only rows that are visible are requested.
+type TableDataProvider interface {
+	// RowCount returns total number of rows.
+	RowCount() int
+	// Row returns texts for each column in row. First row is 0.
+	Row(index int) []string
+}
+
+// VirtualTable is a table that does not store its rows. Instead it requests visible rows from TableDataProvider
+// every time it is drawn. Use it for very large datasets. Like Table, user can sort columns by moving to header
+// row and pressing Enter, or by clicking header, after which sortFunc is called and provider is expected to
+// return rows in new order. Selection and scroll position are kept as long as rows exist.
+type VirtualTable struct {
+	*cview.Box
+
+	provider         TableDataProvider
+	columns          []string
+	columnWidths     []int
+	columnExpansions []int
+	showIndex        bool
+
+	// index of selected row
+	selected int
+	// index of first visible row
+	offset int
+	// number of visible data rows during last draw
+	visibleRows int
+	// x position and width of each column during last draw
+	columnX     []int
+	columnWidth []int
+
+	headerSelected bool
+	headerColumn   int
+	sortCol        int
+	sortType       Sort
+
+	sortFunc     func(column string, sort Sort)
+	selectedFunc func(index int)
+	changedFunc  func(index int)
+
+	headerColor   tcell.Color
+	textColor     tcell.Color
+	selectedColor tcell.Color
+	selectedText  tcell.Color
+}
+
+// NewVirtualTable creates new virtual table that gets its rows from provider.
+func NewVirtualTable(provider TableDataProvider) *VirtualTable {
+	v := &VirtualTable{
+		Box:           cview.NewBox(),
+		provider:      provider,
+		sortCol:       -1,
+		sortType:      SortAsc,
+		headerColor:   cview.Styles.SecondaryTextColor,
+		textColor:     cview.Styles.PrimaryTextColor,
+		selectedColor: cview.Styles.PrimaryTextColor,
+		selectedText:  cview.Styles.PrimitiveBackgroundColor,
+	}
+	return v
+}
+
+// SetProvider sets new provider. Selection and offset are retained if possible.
+func (v *VirtualTable) SetProvider(provider TableDataProvider) *VirtualTable {
+	v.provider = provider
+	v.clamp()
+	return v
+}
+
+// SetColumns sets column header names.
+func (v *VirtualTable) SetColumns(columns []string) *VirtualTable {
+	v.columns = columns
+	return v
+}
+
+// SetShowIndex configures whether first column is row index, starting from 1.
+func (v *VirtualTable) SetShowIndex(index bool) *VirtualTable {
+	v.showIndex = index
+	return v
+}
+
+// SetColumnWidths sets each columns maximum width. If index is shown, it must be included in here.
+func (v *VirtualTable) SetColumnWidths(widths []int) *VirtualTable {
+	v.columnWidths = widths
+	return v
+}
+
+// SetColumnExpansions sets how each column will expand when there's more space than needed.
+// If index is shown, it must be included in here.
+func (v *VirtualTable) SetColumnExpansions(expansions []int) *VirtualTable {
+	v.columnExpansions = expansions
+	return v
+}
+
+// SetColors sets colors for header, text and selected row.
+func (v *VirtualTable) SetColors(header, text, selectedBackground, selectedText tcell.Color) *VirtualTable {
+	v.headerColor = header
+	v.textColor = text
+	v.selectedColor = selectedBackground
+	v.selectedText = selectedText
+	return v
+}
+
+// SetSortFunc sets function that gets called when user sorts by column. Column excludes index column.
+func (v *VirtualTable) SetSortFunc(sortFunc func(column string, sort Sort)) *VirtualTable {
+	v.sortFunc = sortFunc
+	return v
+}
+
+// SetSort sets current sort column and direction without calling sortFunc. Column excludes index column.
+func (v *VirtualTable) SetSort(column int, sort Sort) *VirtualTable {
+	v.sortCol = column
+	v.sortType = sort
+	return v
+}
+
+// SetSelectedFunc sets function that gets called when user presses Enter or double-clicks row.
+func (v *VirtualTable) SetSelectedFunc(selected func(index int)) *VirtualTable {
+	v.selectedFunc = selected
+	return v
+}
+
+// SetSelectionChangedFunc sets function that gets called when selected row changes.
+func (v *VirtualTable) SetSelectionChangedFunc(changed func(index int)) *VirtualTable {
+	v.changedFunc = changed
+	return v
+}
+
+// GetSelected returns selected row index.
+func (v *VirtualTable) GetSelected() int {
+	return v.selected
+}
+
+// Select selects row and scrolls it visible.
+func (v *VirtualTable) Select(index int) *VirtualTable {
+	v.setSelected(index)
+	return v
+}
+
+// GetOffset returns index of first visible row.
+func (v *VirtualTable) GetOffset() int {
+	return v.offset
+}
+
+// SetOffset sets index of first visible row.
+func (v *VirtualTable) SetOffset(offset int) *VirtualTable {
+	v.offset = offset
+	v.clamp()
+	return v
+}
+
+// rowCount returns number of rows in provider
+func (v *VirtualTable) rowCount() int {
+	if v.provider == nil {
+		return 0
+	}
+	return v.provider.RowCount()
+}
+
+// clamp keeps selection and offset inside provider rows
+func (v *VirtualTable) clamp() {
+	count := v.rowCount()
+	v.selected = max(0, min(v.selected, count-1))
+	v.offset = max(0, min(v.offset, count-v.visibleRows))
+}
+
+// setSelected changes selection and scrolls it visible
+func (v *VirtualTable) setSelected(index int) {
+	last := v.selected
+	v.selected = index
+	v.clamp()
+	if v.selected < v.offset {
+		v.offset = v.selected
+	} else if v.visibleRows > 0 && v.selected >= v.offset+v.visibleRows {
+		v.offset = v.selected - v.visibleRows + 1
+	}
+	if last != v.selected && v.changedFunc != nil {
+		v.changedFunc(v.selected)
+	}
+}
+
+// sort sorts by header column
+func (v *VirtualTable) sort(column int) {
+	if column < 0 || column >= len(v.columns) {
+		return
+	}
+	if column == v.sortCol {
+		if v.sortType == SortAsc {
+			v.sortType = SortDesc
+		} else {
+			v.sortType = SortAsc
+		}
+	} else {
+		v.sortCol = column
+		v.sortType = SortAsc
+	}
+	if v.sortFunc != nil {
+		v.sortFunc(v.columns[column], v.sortType)
+	}
+	v.clamp()
+}
+
+// headerTexts returns header row texts including index and sort arrow
+func (v *VirtualTable) headerTexts() []string {
+	texts := make([]string, 0, len(v.columns)+1)
+	if v.showIndex {
+		texts = append(texts, "#")
+	}
+	for i, name := range v.columns {
+		if i == v.sortCol {
+			arrow := arrowDown
+			if v.sortType == SortDesc {
+				arrow = arrowUp
+			}
+			name = fmt.Sprintf("%s %s", name, arrow)
+		}
+		texts = append(texts, name)
+	}
+	return texts
+}
+
+// rowTexts returns row texts including index column
+func (v *VirtualTable) rowTexts(index int) []string {
+	row := v.provider.Row(index)
+	if v.showIndex {
+		return append([]string{strconv.Itoa(index + 1)}, row...)
+	}
+	return row
+}
+
+// Draw draws visible rows.
+func (v *VirtualTable) Draw(screen tcell.Screen) {
+	v.Box.Draw(screen)
+	x, y, width, height := v.GetInnerRect()
+	if height < 1 || width < 1 {
+		return
+	}
+
+	v.visibleRows = height - 1
+	v.clamp()
+
+	header := v.headerTexts()
+	rows := make([][]string, 0, v.visibleRows)
+	count := v.rowCount()
+	for i := v.offset; i < count && i < v.offset+v.visibleRows; i++ {
+		rows = append(rows, v.rowTexts(i))
+	}
+
+	v.layoutColumns(header, rows, x, width)
+
+	// header
+	for col, text := range header {
+		color := v.headerColor
+		if v.headerSelected && v.HasFocus() && col == v.headerColumn+v.indexOffset() {
+			v.fill(screen, col, y, v.selectedColor)
+			color = v.selectedText
+		}
+		v.print(screen, text, col, y, color)
+	}
+
+	// rows
+	for i, row := range rows {
+		rowY := y + 1 + i
+		color := v.textColor
+		if v.offset+i == v.selected && !v.headerSelected {
+			bg := v.selectedColor
+			if !v.HasFocus() {
+				bg = cview.Styles.MoreContrastBackgroundColor
+			}
+			for col := range v.columnX {
+				v.fill(screen, col, rowY, bg)
+			}
+			color = v.selectedText
+		}
+		for col, text := range row {
+			v.print(screen, text, col, rowY, color)
+		}
+	}
+}
+
+// indexOffset returns 1 if index is shown, else 0
+func (v *VirtualTable) indexOffset() int {
+	if v.showIndex {
+		return 1
+	}
+	return 0
+}
+
+// layoutColumns computes column positions from visible texts
+func (v *VirtualTable) layoutColumns(header []string, rows [][]string, x, width int) {
+	columns := len(header)
+	widths := make([]int, columns)
+	for col := 0; col < columns; col++ {
+		widths[col] = cview.TaggedStringWidth(header[col])
+		for _, row := range rows {
+			if col < len(row) {
+				widths[col] = max(widths[col], cview.TaggedStringWidth(row[col]))
+			}
+		}
+		if col < len(v.columnWidths) && v.columnWidths[col] > 0 {
+			widths[col] = min(widths[col], v.columnWidths[col])
+		}
+	}
+
+	// distribute free space
+	total := columns - 1
+	expansionTotal := 0
+	for col, w := range widths {
+		total += w
+		if col < len(v.columnExpansions) {
+			expansionTotal += v.columnExpansions[col]
+		}
+	}
+	free := width - total
+	for col := 0; col < columns && free > 0 && expansionTotal > 0; col++ {
+		if col >= len(v.columnExpansions) {
+			break
+		}
+		extra := free * v.columnExpansions[col] / expansionTotal
+		widths[col] += extra
+		free -= extra
+		expansionTotal -= v.columnExpansions[col]
+	}
+
+	v.columnX = make([]int, columns)
+	v.columnWidth = make([]int, columns)
+	pos := x
+	for col := 0; col < columns; col++ {
+		v.columnX[col] = pos
+		v.columnWidth[col] = max(0, min(widths[col], x+width-pos))
+		pos += widths[col] + 1
+	}
+}
+
+// print prints text to column
+func (v *VirtualTable) print(screen tcell.Screen, text string, col, y int, color tcell.Color) {
+	if col >= len(v.columnX) || v.columnWidth[col] <= 0 {
+		return
+	}
+	cview.Print(screen, text, v.columnX[col], y, v.columnWidth[col], cview.AlignLeft, color)
+}
+
+// fill fills column background including separator
+func (v *VirtualTable) fill(screen tcell.Screen, col, y int, color tcell.Color) {
+	x, _, width, _ := v.GetInnerRect()
+	style := tcell.StyleDefault.Background(color)
+	end := v.columnX[col] + v.columnWidth[col] + 1
+	for i := v.columnX[col]; i < end && i < x+width; i++ {
+		screen.SetContent(i, y, ' ', nil, style)
+	}
+}
+
+// InputHandler handles moving selection, header and sorting.
+func (v *VirtualTable) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+	return v.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+		key := event.Key()
+		if key == tcell.KeyRune {
+			switch event.Rune() {
+			case 'j':
+				key = tcell.KeyDown
+			case 'k':
+				key = tcell.KeyUp
+			case 'g':
+				key = tcell.KeyHome
+			case 'G':
+				key = tcell.KeyEnd
+			case 'h':
+				key = tcell.KeyLeft
+			case 'l':
+				key = tcell.KeyRight
+			}
+		}
+
+		if v.headerSelected {
+			switch key {
+			case tcell.KeyLeft:
+				v.headerColumn = max(0, v.headerColumn-1)
+			case tcell.KeyRight:
+				v.headerColumn = min(len(v.columns)-1, v.headerColumn+1)
+			case tcell.KeyDown, tcell.KeyEscape:
+				v.headerSelected = false
+			case tcell.KeyEnter:
+				v.sort(v.headerColumn)
+			}
+			return
+		}
+
+		page := max(1, v.visibleRows)
+		switch key {
+		case tcell.KeyUp:
+			if v.selected == 0 && v.sortFunc != nil && len(v.columns) > 0 {
+				v.headerSelected = true
+				v.headerColumn = max(0, v.sortCol)
+				return
+			}
+			v.setSelected(v.selected - 1)
+		case tcell.KeyDown:
+			v.setSelected(v.selected + 1)
+		case tcell.KeyPgUp, tcell.KeyCtrlB:
+			v.setSelected(v.selected - page)
+		case tcell.KeyPgDn, tcell.KeyCtrlF:
+			v.setSelected(v.selected + page)
+		case tcell.KeyHome:
+			v.setSelected(0)
+		case tcell.KeyEnd:
+			v.setSelected(v.rowCount() - 1)
+		case tcell.KeyEnter:
+			if v.selectedFunc != nil && v.rowCount() > 0 {
+				v.selectedFunc(v.selected)
+			}
+		}
+	})
+}
+
+// MouseHandler handles clicking header to sort, clicking row to select and scrolling.
+func (v *VirtualTable) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+	return v.WrapMouseHandler(func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+		x, y := event.Position()
+		if !v.InRect(x, y) {
+			return false, nil
+		}
+		_, innerY, _, _ := v.GetInnerRect()
+
+		switch action {
+		case cview.MouseLeftClick, cview.MouseLeftDoubleClick:
+			setFocus(v)
+			if y == innerY {
+				col := v.columnAt(x) - v.indexOffset()
+				if col >= 0 && v.sortFunc != nil {
+					v.sort(col)
+				}
+				return true, nil
+			}
+			index := v.offset + y - innerY - 1
+			if index >= 0 && index < v.rowCount() {
+				v.headerSelected = false
+				v.setSelected(index)
+				if action == cview.MouseLeftDoubleClick && v.selectedFunc != nil {
+					v.selectedFunc(v.selected)
+				}
+			}
+			return true, nil
+		case cview.MouseScrollUp:
+			v.SetOffset(v.offset - 1)
+			return true, nil
+		case cview.MouseScrollDown:
+			v.SetOffset(v.offset + 1)
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+// columnAt returns column at screen x, or -1
+func (v *VirtualTable) columnAt(x int) int {
+	for col := range v.columnX {
+		if x >= v.columnX[col] && x <= v.columnX[col]+v.columnWidth[col] {
+			return col
+		}
+	}
+	return -1
+}
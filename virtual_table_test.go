@@ -0,0 +1,108 @@
+/*
+ * Copyright 2020 Tero Vierimaa
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package twidgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"strconv"
+	"testing"
+)
+
+// generatedRows creates rows on demand without storing them
+type generatedRows int
+
+func (g generatedRows) RowCount() int {
+	return int(g)
+}
+
+func (g generatedRows) Row(index int) []string {
+	return []string{"row " + strconv.Itoa(index), strconv.Itoa(index * 7 % 1000)}
+}
+
+func newTestScreen(tb testing.TB) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		tb.Fatal(err)
+	}
+	screen.SetSize(80, 25)
+	return screen
+}
+
+func TestVirtualTable_selection(t *testing.T) {
+	table := NewVirtualTable(generatedRows(1000))
+	table.SetColumns([]string{"Name", "Value"})
+	table.SetShowIndex(true)
+	table.SetRect(0, 0, 80, 25)
+	screen := newTestScreen(t)
+	table.Draw(screen)
+
+	input := table.InputHandler()
+	input(tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone), nil)
+	input(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), nil)
+	if table.GetSelected() != 25 {
+		t.Errorf("selected: got %d, want 25", table.GetSelected())
+	}
+	if table.GetOffset() != 2 {
+		t.Errorf("offset: got %d, want 2", table.GetOffset())
+	}
+
+	table.SetProvider(generatedRows(10))
+	table.Draw(screen)
+	if table.GetSelected() != 9 || table.GetOffset() != 0 {
+		t.Errorf("after shrinking provider: got selected %d, offset %d", table.GetSelected(), table.GetOffset())
+	}
+}
+
+// BenchmarkVirtualTable_Draw shows that drawing allocates the same amount of memory
+// regardless of row count.
+func BenchmarkVirtualTable_Draw(b *testing.B) {
+	for _, rows := range []int{1000, 1000000, 100000000} {
+		b.Run(fmt.Sprintf("rows-%d", rows), func(b *testing.B) {
+			table := NewVirtualTable(generatedRows(rows))
+			table.SetColumns([]string{"Name", "Value"})
+			table.SetShowIndex(true)
+			table.SetRect(0, 0, 80, 25)
+			table.Select(rows / 2)
+			screen := newTestScreen(b)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				table.Draw(screen)
+			}
+		})
+	}
+}
+
+// BenchmarkTable_AddRow shows memory needed to fill Table with rows, for comparison.
+func BenchmarkTable_AddRow(b *testing.B) {
+	for _, rows := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("rows-%d", rows), func(b *testing.B) {
+			provider := generatedRows(rows)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				table := NewTable()
+				table.SetShowIndex(true)
+				table.SetColumns([]string{"Name", "Value"})
+				for row := 0; row < rows; row++ {
+					table.AddRow(row, provider.Row(row)...)
+				}
+			}
+		})
+	}
+}